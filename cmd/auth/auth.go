@@ -0,0 +1,62 @@
+// Package auth provides a `gh models auth` command to inspect how the extension authenticates with GitHub.
+package auth
+
+import (
+	"fmt"
+
+	"github.com/github/gh-models/pkg/command"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+)
+
+// NewAuthCommand returns a new command to inspect authentication.
+func NewAuthCommand(cfg *command.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth <command>",
+		Short: "Inspect authentication with GitHub",
+		Annotations: map[string]string{
+			command.AnnotationSkipClient: "true",
+		},
+	}
+
+	cmd.AddCommand(newStatusCommand(cfg))
+
+	return cmd
+}
+
+func newStatusCommand(cfg *command.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the GitHub host and token in use",
+		Long: heredoc.Docf(`
+			Shows which GitHub host the extension uses and where its token comes from. The token itself is
+			not printed.
+
+			The host is taken from %[1]s--hostname%[1]s, then %[1]sGH_HOST%[1]s, then the default host in the gh config.
+			The token is taken from %[1]sGH_TOKEN%[1]s, then %[1]sGITHUB_TOKEN%[1]s, then the token stored by
+			%[1]sgh auth login%[1]s. For GitHub Enterprise Server hosts, %[1]sGH_ENTERPRISE_TOKEN%[1]s and
+			%[1]sGITHUB_ENTERPRISE_TOKEN%[1]s are used instead of the first two.
+		`, "`"),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a := cfg.Auth
+			if a == nil {
+				a = command.ResolveAuth("", cfg.Settings)
+			}
+
+			cfg.WriteToOut(fmt.Sprintf("Host: %s (from %s)\n", a.Host, a.DescribeHostSource()))
+			if a.Token == "" {
+				cfg.WriteToOut("Token: none. Run 'gh auth login' or set GH_TOKEN to authenticate.\n")
+			} else {
+				cfg.WriteToOut(fmt.Sprintf("Token: %s (from %s)\n", a.MaskedToken(), a.DescribeTokenSource()))
+			}
+			if a.Endpoints == nil {
+				cfg.WriteToOut(fmt.Sprintf("Endpoints: unknown. Set them with 'gh models config set hosts.%[1]s.inference-url <url>' and 'gh models config set hosts.%[1]s.catalog-url <url>'.\n", a.Host))
+			} else {
+				cfg.WriteToOut(fmt.Sprintf("Inference URL: %s\n", a.Endpoints.InferenceURL))
+				cfg.WriteToOut(fmt.Sprintf("Catalog URL: %s\n", a.Endpoints.AzureAiStudioURL))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth(t *testing.T) {
+	t.Run("status shows the host and token source without the token", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, nil, false, 80)
+		cfg.Auth = &command.Auth{
+			Host:        "github.com",
+			HostSource:  "GH_HOST",
+			Token:       "gho_secret123",
+			TokenSource: "GH_TOKEN",
+			Endpoints:   azuremodels.NewDefaultAzureClientConfig(),
+		}
+		cmd := NewAuthCommand(cfg)
+		cmd.SetArgs([]string{"status"})
+
+		_, err := cmd.ExecuteC()
+
+		require.NoError(t, err)
+		output := buf.String()
+		require.NotContains(t, output, "secret123")
+		require.Contains(t, output, "Host: github.com (from GH_HOST)\n")
+		require.Contains(t, output, "Token: gho_********* (from GH_TOKEN)\n")
+		require.Contains(t, output, "Inference URL: https://models.inference.ai.azure.com/chat/completions\n")
+	})
+
+	t.Run("status explains how to authenticate and configure unknown hosts", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, nil, false, 80)
+		cfg.Auth = &command.Auth{Host: "octocorp.ghe.com", HostSource: "--hostname", TokenSource: "default"}
+		cmd := NewAuthCommand(cfg)
+		cmd.SetArgs([]string{"status"})
+
+		_, err := cmd.ExecuteC()
+
+		require.NoError(t, err)
+		output := buf.String()
+		require.Contains(t, output, "Host: octocorp.ghe.com (from --hostname)\n")
+		require.Contains(t, output, "Token: none. Run 'gh auth login' or set GH_TOKEN to authenticate.\n")
+		require.Contains(t, output, "hosts.octocorp.ghe.com.inference-url")
+	})
+}
@@ -0,0 +1,169 @@
+// Package catalog provides a `gh models catalog` command to track changes to the model catalog.
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	modelcatalog "github.com/github/gh-models/internal/catalog"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lightGrayUnderline = ansi.ColorFunc("white+du")
+	changeColors       = map[string]func(string) string{
+		modelcatalog.ChangeAdded:   ansi.ColorFunc("green"),
+		modelcatalog.ChangeRemoved: ansi.ColorFunc("red"),
+		modelcatalog.ChangeChanged: ansi.ColorFunc("yellow"),
+	}
+)
+
+// NewCatalogCommand returns a new command to snapshot the model catalog and compare snapshots.
+func NewCatalogCommand(cfg *command.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "catalog <command>",
+		Short: "Track changes to the available models",
+		Long: heredoc.Doc(`
+			Saves snapshots of the available models and their details, and reports what changed between
+			snapshots, or since the last snapshot.
+
+			Changes are models that were added or removed, and changes to the version, context limits,
+			rate limit tier or license of a model.
+		`),
+		Example: heredoc.Doc(`
+			gh models catalog snapshot
+			gh models catalog diff
+			gh models catalog diff --json monday.json
+		`),
+	}
+
+	cmd.AddCommand(newSnapshotCommand(cfg))
+	cmd.AddCommand(newDiffCommand(cfg))
+
+	return cmd
+}
+
+func newSnapshotCommand(cfg *command.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "snapshot [file]",
+		Short: "Save a snapshot of the available models",
+		Long: heredoc.Docf(`
+			Saves the available models and their details to the given file, as JSON. Without a file, the
+			snapshot replaces the last snapshot, which %[1]sgh models catalog diff%[1]s compares against.
+		`, "`"),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := lastSnapshotPath(cfg)
+			if len(args) == 1 {
+				path = args[0]
+			}
+
+			live, err := cfg.NewCatalogLoader().Load(cmd.Context(), true)
+			if err != nil {
+				return err
+			}
+			if err := live.WriteFile(path); err != nil {
+				return fmt.Errorf("failed to save the snapshot: %w", err)
+			}
+
+			if cfg.IsTerminalOutput {
+				cfg.WriteToOut(fmt.Sprintf("Saved a snapshot of %d models to %s\n", len(live.Models), path))
+			}
+			return nil
+		},
+	}
+}
+
+func newDiffCommand(cfg *command.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [old] [new]",
+		Short: "Report changes between snapshots of the available models",
+		Long: heredoc.Docf(`
+			Reports the changes from the %[1]sold%[1]s snapshot to the %[1]snew%[1]s one. Without %[1]snew%[1]s, the
+			old snapshot is compared with the models available now, and without either, the last snapshot
+			saved by %[1]sgh models catalog snapshot%[1]s is.
+
+			Use %[1]s--json%[1]s to write the changes as JSON, for example in a scheduled job.
+		`, "`"),
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsonOutput, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			oldPath := lastSnapshotPath(cfg)
+			if len(args) > 0 {
+				oldPath = args[0]
+			}
+			oldCatalog, err := modelcatalog.ReadFile(oldPath)
+			if errors.Is(err, fs.ErrNotExist) && len(args) == 0 {
+				return fmt.Errorf("no snapshot found at %s. Run 'gh models catalog snapshot' to save one", oldPath)
+			}
+			if err != nil {
+				return err
+			}
+
+			var newCatalog *modelcatalog.Catalog
+			if len(args) == 2 {
+				newCatalog, err = modelcatalog.ReadFile(args[1])
+			} else {
+				newCatalog, err = cfg.NewCatalogLoader().Load(cmd.Context(), true)
+			}
+			if err != nil {
+				return err
+			}
+
+			changes := modelcatalog.Diff(oldCatalog, newCatalog)
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(changes, "", "  ")
+				if err != nil {
+					return err
+				}
+				cfg.WriteToOut(string(data) + "\n")
+				return nil
+			}
+
+			if len(changes) == 0 {
+				if cfg.IsTerminalOutput {
+					cfg.WriteToOut("No changes\n")
+				}
+				return nil
+			}
+
+			printer := cfg.NewTablePrinter()
+			printer.AddHeader([]string{"MODEL", "CHANGE", "FIELD", "OLD", "NEW"}, tableprinter.WithColor(lightGrayUnderline))
+			printer.EndRow()
+
+			for _, change := range changes {
+				printer.AddField(change.Model)
+				printer.AddField(change.Kind, tableprinter.WithColor(changeColors[change.Kind]))
+				printer.AddField(change.Field)
+				printer.AddField(change.Old)
+				printer.AddField(change.New)
+				printer.EndRow()
+			}
+
+			return printer.Render()
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Write the changes as JSON.")
+
+	return cmd
+}
+
+func lastSnapshotPath(cfg *command.Config) string {
+	host := ""
+	if cfg.Auth != nil {
+		host = cfg.Auth.Host
+	}
+	return modelcatalog.SnapshotPath(host)
+}
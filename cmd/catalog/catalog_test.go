@@ -0,0 +1,68 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog(t *testing.T) {
+	newClient := func(version string) *azuremodels.MockClient {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{{Name: "gpt-4o", Task: "chat-completion", Version: version}}, nil
+		}
+		client.MockGetModelDetails = func(ctx context.Context, registry, modelName, version string) (*azuremodels.ModelDetails, error) {
+			return &azuremodels.ModelDetails{License: "custom"}, nil
+		}
+		return client
+	}
+
+	run := func(client azuremodels.Client, args ...string) (string, error) {
+		buf := new(bytes.Buffer)
+		cmd := NewCatalogCommand(command.NewConfig(buf, buf, client, false, 80))
+		cmd.SetArgs(args)
+		_, err := cmd.ExecuteC()
+		return buf.String(), err
+	}
+
+	t.Run("diffs the last snapshot against the live catalog", func(t *testing.T) {
+		t.Setenv("XDG_CACHE_HOME", t.TempDir())
+		t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+		_, err := run(newClient("1"), "diff")
+		require.ErrorContains(t, err, "no snapshot found at ")
+
+		_, err = run(newClient("1"), "snapshot")
+		require.NoError(t, err)
+
+		output, err := run(newClient("1"), "diff")
+		require.NoError(t, err)
+		require.Empty(t, output)
+
+		output, err = run(newClient("2"), "diff")
+		require.NoError(t, err)
+		require.Equal(t, "\ngpt-4o\tchanged\tversion\t1\t2\n", output)
+	})
+
+	t.Run("diffs two snapshots as JSON", func(t *testing.T) {
+		t.Setenv("XDG_CACHE_HOME", t.TempDir())
+		dir := t.TempDir()
+		oldPath, newPath := filepath.Join(dir, "old.json"), filepath.Join(dir, "new.json")
+
+		_, err := run(newClient("1"), "snapshot", oldPath)
+		require.NoError(t, err)
+		_, err = run(newClient("2"), "snapshot", newPath)
+		require.NoError(t, err)
+
+		output, err := run(nil, "diff", "--json", oldPath, newPath)
+
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"model": "gpt-4o", "change": "changed", "field": "version", "old": "1", "new": "2"}]`, output)
+	})
+}
@@ -0,0 +1,434 @@
+// Package compare provides a `gh models compare` command to run the same prompt against several models.
+package compare
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/github/gh-models/cmd/run"
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/tokens"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/github/gh-models/pkg/util"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lightGrayUnderline = ansi.ColorFunc("white+du")
+	sectionHeaderColor = ansi.ColorFunc("white+b")
+)
+
+// NewCompareCommand returns a new command to compare the responses of several models to the same prompt.
+func NewCompareCommand(cfg *command.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compare [prompt]",
+		Short: "Compare the responses of several models to a prompt",
+		Long: heredoc.Docf(`
+			Sends the same prompt to each of the models given with %[1]s--model%[1]s at the same time.
+
+			Each response is written in its own section as it streams in. Responses that arrive while
+			another one is being written are shown once it is complete. A summary table of the latency,
+			token usage and finish reason of each model follows the responses.
+
+			Use %[1]s--json%[1]s to write the responses and their statistics as JSON instead, for later analysis.
+			Token counts are estimated when a model does not report its usage.
+		`, "`"),
+		Example: "gh models compare -m gpt-4o -m Llama-3.3-70B-Instruct \"how many types of hyena are there?\"",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+
+			modelNames, err := flags.GetStringArray("model")
+			if err != nil {
+				return err
+			}
+			if len(modelNames) < 2 {
+				return errors.New("specify at least two models to compare with --model")
+			}
+
+			profileName, err := flags.GetString("profile")
+			if err != nil {
+				return err
+			}
+			profile, err := cfg.Settings.Resolve(profileName)
+			if err != nil {
+				return err
+			}
+
+			systemPrompt, err := flags.GetString("system-prompt")
+			if err != nil {
+				return err
+			}
+			if !flags.Changed("system-prompt") {
+				systemPrompt = profile.SystemPrompt
+			}
+
+			jsonOutput, err := flags.GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			mp := run.ModelParameters{}
+			err = mp.PopulateFromSettings(profile.Parameters)
+			if err != nil {
+				return err
+			}
+			err = mp.PopulateFromFlags(flags)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			models, err := cfg.Client.ListModels(ctx)
+			if err != nil {
+				return err
+			}
+
+			messages := []azuremodels.ChatMessage{}
+			if systemPrompt != "" {
+				messages = append(messages, azuremodels.ChatMessage{
+					Content: util.Ptr(systemPrompt),
+					Role:    azuremodels.ChatMessageRoleSystem,
+				})
+			}
+			messages = append(messages, azuremodels.ChatMessage{
+				Content: util.Ptr(strings.Join(args, " ")),
+				Role:    azuremodels.ChatMessageRoleUser,
+			})
+
+			requests := make([]azuremodels.ChatCompletionOptions, len(modelNames))
+			for i, modelName := range modelNames {
+				modelName = cfg.Settings.ResolveModel(modelName, profile)
+				if err := azuremodels.CheckProvider(cfg.Client, modelName); err != nil {
+					return err
+				}
+				name, err := resolveModelName(modelName, models)
+				if err != nil {
+					return err
+				}
+				requests[i] = azuremodels.ChatCompletionOptions{
+					Messages: messages,
+					Model:    name,
+				}
+				mp.UpdateRequest(&requests[i])
+			}
+
+			handler := &compareCommandHandler{ctx: ctx, cfg: cfg}
+			results := handler.compare(requests, !jsonOutput)
+
+			if jsonOutput {
+				return handler.writeJSON(results)
+			}
+
+			handler.writeSummary(results)
+
+			for _, result := range results {
+				if result.Error == "" {
+					return nil
+				}
+			}
+			return errors.New("none of the models returned a response")
+		},
+	}
+
+	cmd.Flags().StringArrayP("model", "m", nil, "A model to compare. Specify at least two.")
+	cmd.Flags().String("system-prompt", "", "Prompt the system.")
+	cmd.Flags().String("profile", "", "Use the settings from this profile in the config file.")
+	cmd.Flags().Bool("json", false, "Write the responses and their statistics as JSON.")
+	run.AddModelParameterFlags(cmd.Flags())
+
+	return cmd
+}
+
+// resolveModelName returns the name of the model matching the given name, which can pin a version as
+// "<model>@<version>".
+func resolveModelName(modelName string, models []*azuremodels.ModelSummary) (string, error) {
+	modelName, version := azuremodels.SplitModelVersion(modelName)
+	model, err := azuremodels.FindModel(models, modelName)
+	var notFound *azuremodels.ModelNotFoundError
+	if errors.As(err, &notFound) {
+		return "", fmt.Errorf("%w. Run 'gh models list' to see available models", err)
+	}
+	if err != nil {
+		return "", err
+	}
+	return model.Name, model.CheckVersion(version)
+}
+
+// compareResult is the response of one model and its statistics.
+type compareResult struct {
+	Model            string `json:"model"`
+	Content          string `json:"content"`
+	FinishReason     string `json:"finish_reason,omitempty"`
+	LatencyMs        int64  `json:"latency_ms"`
+	FirstTokenMs     int64  `json:"first_token_ms"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	TokensEstimated  bool   `json:"tokens_estimated"`
+	Error            string `json:"error,omitempty"`
+}
+
+// compareEvent reports progress from one of the models: either the next chunk of its response, or that it is done.
+type compareEvent struct {
+	index   int
+	content string
+	done    bool
+}
+
+type compareCommandHandler struct {
+	ctx context.Context
+	cfg *command.Config
+}
+
+// compare sends each request concurrently, and returns the results in the same order. If write is true, the responses
+// are written as they stream in.
+func (h *compareCommandHandler) compare(requests []azuremodels.ChatCompletionOptions, write bool) []*compareResult {
+	sp := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(h.cfg.ErrOut))
+	sp.Start()
+	defer sp.Stop()
+
+	results := make([]*compareResult, len(requests))
+	events := make(chan compareEvent)
+	for i, req := range requests {
+		results[i] = &compareResult{Model: req.Model}
+		go h.stream(req, i, results[i], events)
+	}
+
+	sections := newSectionWriter(h.cfg, results)
+	for remaining := len(requests); remaining > 0; {
+		event := <-events
+		if event.done {
+			remaining--
+		}
+		if !write {
+			continue
+		}
+		sp.Stop()
+		sections.handle(event)
+	}
+
+	return results
+}
+
+// stream sends the request, and fills in the result as the response streams in. Progress is reported on events.
+func (h *compareCommandHandler) stream(req azuremodels.ChatCompletionOptions, index int, result *compareResult, events chan<- compareEvent) {
+	start := time.Now()
+	var content strings.Builder
+	var usage *azuremodels.ChatCompletionUsage
+
+	err := func() error {
+		resp, err := h.cfg.Client.GetChatCompletionStream(h.ctx, req)
+		if err != nil {
+			return err
+		}
+		defer resp.Reader.Close()
+
+		for {
+			completion, err := resp.Reader.Read()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+
+			if completion.Usage != nil {
+				usage = completion.Usage
+			}
+
+			for _, choice := range completion.Choices {
+				// Only the first candidate is compared, if several were requested.
+				if choice.Index != 0 {
+					continue
+				}
+				if choice.FinishReason != "" {
+					result.FinishReason = choice.FinishReason
+				}
+
+				var chunk *string
+				if choice.Delta != nil {
+					chunk = choice.Delta.Content
+				} else if choice.Message != nil {
+					chunk = choice.Message.Content
+				}
+				if chunk == nil || *chunk == "" {
+					continue
+				}
+
+				if content.Len() == 0 {
+					result.FirstTokenMs = time.Since(start).Milliseconds()
+				}
+				content.WriteString(*chunk)
+				events <- compareEvent{index: index, content: *chunk}
+			}
+		}
+	}()
+
+	result.LatencyMs = time.Since(start).Milliseconds()
+	result.Content = content.String()
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if usage != nil {
+		result.PromptTokens = usage.PromptTokens
+		result.CompletionTokens = usage.CompletionTokens
+	} else {
+		result.PromptTokens = tokens.EstimateMessages(req.Messages)
+		result.CompletionTokens = tokens.Estimate(result.Content)
+		result.TokensEstimated = true
+	}
+
+	events <- compareEvent{index: index, done: true}
+}
+
+// writeJSON writes the results as a JSON array.
+func (h *compareCommandHandler) writeJSON(results []*compareResult) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	h.cfg.WriteToOut(string(data) + "\n")
+	return nil
+}
+
+// writeSummary writes a table of the statistics of each model.
+func (h *compareCommandHandler) writeSummary(results []*compareResult) {
+	printer := h.cfg.NewTablePrinter()
+
+	printer.AddHeader([]string{"MODEL", "LATENCY", "FIRST TOKEN", "PROMPT TOKENS", "COMPLETION TOKENS", "FINISH REASON"}, tableprinter.WithColor(lightGrayUnderline))
+	printer.EndRow()
+
+	for _, result := range results {
+		finishReason := result.FinishReason
+		if result.Error != "" {
+			finishReason = "error"
+		}
+		firstToken := "-"
+		if result.Content != "" {
+			firstToken = formatMilliseconds(result.FirstTokenMs)
+		}
+
+		printer.AddField(result.Model)
+		printer.AddField(formatMilliseconds(result.LatencyMs))
+		printer.AddField(firstToken)
+		printer.AddField(formatTokens(result.PromptTokens, result.TokensEstimated))
+		printer.AddField(formatTokens(result.CompletionTokens, result.TokensEstimated))
+		printer.AddField(finishReason)
+		printer.EndRow()
+	}
+
+	err := printer.Render()
+	if err != nil {
+		util.WriteToOut(h.cfg.ErrOut, "Error rendering summary: "+err.Error()+"\n")
+	}
+}
+
+func formatMilliseconds(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).Round(10 * time.Millisecond).String()
+}
+
+func formatTokens(count int, estimated bool) string {
+	if estimated {
+		return "~" + strconv.Itoa(count)
+	}
+	return strconv.Itoa(count)
+}
+
+// sectionWriter writes each model's response in its own section. The first response to arrive is written as it
+// streams in, while the others are buffered until it is complete.
+type sectionWriter struct {
+	cfg     *command.Config
+	results []*compareResult
+	buffers []strings.Builder
+	started []bool
+	done    []bool
+	// live is the index of the response currently being streamed, or -1 if there is none.
+	live int
+}
+
+func newSectionWriter(cfg *command.Config, results []*compareResult) *sectionWriter {
+	return &sectionWriter{
+		cfg:     cfg,
+		results: results,
+		buffers: make([]strings.Builder, len(results)),
+		started: make([]bool, len(results)),
+		done:    make([]bool, len(results)),
+		live:    -1,
+	}
+}
+
+func (w *sectionWriter) handle(event compareEvent) {
+	i := event.index
+
+	if event.done {
+		w.done[i] = true
+		if w.live == i {
+			w.end(i)
+			w.live = -1
+		}
+		if w.live == -1 {
+			w.next()
+		}
+		return
+	}
+
+	w.buffers[i].WriteString(event.content)
+	switch w.live {
+	case -1:
+		w.start(i)
+		w.live = i
+	case i:
+		w.cfg.WriteToOut(event.content)
+	}
+}
+
+// next writes the responses that completed while another one was streaming, then starts streaming the next response
+// that has begun to arrive.
+func (w *sectionWriter) next() {
+	for i := range w.results {
+		if w.done[i] && !w.started[i] {
+			w.start(i)
+			w.end(i)
+		}
+	}
+	for i := range w.results {
+		if !w.started[i] && w.buffers[i].Len() > 0 {
+			w.start(i)
+			w.live = i
+			return
+		}
+	}
+}
+
+// start writes the section header and anything buffered for the response.
+func (w *sectionWriter) start(i int) {
+	w.started[i] = true
+	header := w.results[i].Model + ":"
+	if w.cfg.IsTerminalOutput {
+		header = sectionHeaderColor(header)
+	}
+	w.cfg.WriteToOut(header + "\n" + w.buffers[i].String())
+}
+
+// end finishes the section, and reports the error if the request failed.
+func (w *sectionWriter) end(i int) {
+	content := w.buffers[i].String()
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		w.cfg.WriteToOut("\n")
+	}
+	if w.results[i].Error != "" {
+		w.cfg.WriteToOut("Error: " + w.results[i].Error + "\n")
+	}
+	w.cfg.WriteToOut("\n")
+}
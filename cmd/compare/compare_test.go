@@ -0,0 +1,156 @@
+package compare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/sse"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/github/gh-models/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	models := []*azuremodels.ModelSummary{
+		{Name: "test-model-1", FriendlyName: "Test Model 1", Task: "chat-completion"},
+		{Name: "test-model-2", FriendlyName: "Test Model 2", Task: "chat-completion"},
+	}
+
+	newClient := func(responses map[string]string) (*azuremodels.MockClient, *[]azuremodels.ChatCompletionOptions) {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return models, nil
+		}
+		var mu sync.Mutex
+		requests := []azuremodels.ChatCompletionOptions{}
+		client.MockGetChatCompletionStream = func(ctx context.Context, req azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			mu.Lock()
+			requests = append(requests, req)
+			mu.Unlock()
+
+			content, ok := responses[req.Model]
+			if !ok {
+				return nil, errors.New("the model is unavailable")
+			}
+			completion := azuremodels.ChatCompletion{
+				Choices: []azuremodels.ChatChoice{{
+					Message: &azuremodels.ChatChoiceMessage{
+						Content: util.Ptr(content),
+						Role:    util.Ptr(string(azuremodels.ChatMessageRoleAssistant)),
+					},
+					FinishReason: azuremodels.FinishReasonStop,
+				}},
+				Usage: &azuremodels.ChatCompletionUsage{PromptTokens: 12, CompletionTokens: 3},
+			}
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{completion}),
+			}, nil
+		}
+		return client, &requests
+	}
+
+	t.Run("writes each response in its own section followed by a summary", func(t *testing.T) {
+		client, requests := newClient(map[string]string{
+			"test-model-1": "response from model 1",
+			"test-model-2": "response from model 2",
+		})
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, client, false, 80)
+		cmd := NewCompareCommand(cfg)
+		cmd.SetArgs([]string{"-m", "test-model-1", "-m", "Test Model 2", "--temperature", "0.5", "this is my prompt"})
+
+		_, err := cmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Len(t, *requests, 2)
+		for _, req := range *requests {
+			require.Equal(t, "this is my prompt", *req.Messages[0].Content)
+			require.Equal(t, 0.5, *req.Temperature)
+		}
+		output := buf.String()
+		require.Contains(t, output, "test-model-1:\nresponse from model 1\n")
+		require.Contains(t, output, "test-model-2:\nresponse from model 2\n")
+		require.Regexp(t, regexp.MustCompile(`test-model-2\t[^\t]+\t[^\t]+\t12\t3\tstop`), output)
+	})
+
+	t.Run("--json writes the results as JSON", func(t *testing.T) {
+		client, _ := newClient(map[string]string{
+			"test-model-1": "response from model 1",
+		})
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, new(bytes.Buffer), client, false, 80)
+		cmd := NewCompareCommand(cfg)
+		cmd.SetArgs([]string{"-m", "test-model-1", "-m", "test-model-2", "--json", "this is my prompt"})
+
+		_, err := cmd.ExecuteC()
+
+		require.NoError(t, err)
+		var results []compareResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+		require.Len(t, results, 2)
+		require.Equal(t, "test-model-1", results[0].Model)
+		require.Equal(t, "response from model 1", results[0].Content)
+		require.Equal(t, azuremodels.FinishReasonStop, results[0].FinishReason)
+		require.Equal(t, 12, results[0].PromptTokens)
+		require.Equal(t, 3, results[0].CompletionTokens)
+		require.False(t, results[0].TokensEstimated)
+		require.Equal(t, "test-model-2", results[1].Model)
+		require.Equal(t, "the model is unavailable", results[1].Error)
+	})
+
+	t.Run("requires at least two models", func(t *testing.T) {
+		client, _ := newClient(nil)
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, client, false, 80)
+		cmd := NewCompareCommand(cfg)
+		cmd.SetArgs([]string{"-m", "test-model-1", "this is my prompt"})
+
+		_, err := cmd.ExecuteC()
+
+		require.EqualError(t, err, "specify at least two models to compare with --model")
+	})
+
+	t.Run("rejects unknown models", func(t *testing.T) {
+		client, _ := newClient(nil)
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, client, false, 80)
+		cmd := NewCompareCommand(cfg)
+		cmd.SetArgs([]string{"-m", "test-model-1", "-m", "unknown-model", "this is my prompt"})
+
+		_, err := cmd.ExecuteC()
+
+		require.EqualError(t, err, "the model 'unknown-model' is not found. Run 'gh models list' to see available models")
+	})
+}
+
+func TestSectionWriter(t *testing.T) {
+	t.Run("streams the first response and buffers the others until it is complete", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, nil, false, 80)
+		results := []*compareResult{{Model: "a"}, {Model: "b"}, {Model: "c"}}
+		w := newSectionWriter(cfg, results)
+
+		w.handle(compareEvent{index: 1, content: "b1 "})
+		w.handle(compareEvent{index: 0, content: "a1 "})
+		w.handle(compareEvent{index: 1, content: "b2"})
+		require.Equal(t, "b:\nb1 b2", buf.String())
+
+		w.handle(compareEvent{index: 0, content: "a2"})
+		w.handle(compareEvent{index: 2, content: "c1"})
+		w.handle(compareEvent{index: 0, done: true})
+		require.Equal(t, "b:\nb1 b2", buf.String())
+
+		w.handle(compareEvent{index: 1, done: true})
+		w.handle(compareEvent{index: 2, content: " c2"})
+		results[2].Error = "connection reset"
+		w.handle(compareEvent{index: 2, done: true})
+
+		require.Equal(t, "b:\nb1 b2\n\na:\na1 a2\n\nc:\nc1 c2\nError: connection reset\n\n", buf.String())
+	})
+}
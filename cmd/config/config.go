@@ -0,0 +1,139 @@
+// Package config provides a `gh models config` command to manage the settings file.
+package config
+
+import (
+	"fmt"
+
+	"github.com/github/gh-models/cmd/run"
+	"github.com/github/gh-models/internal/settings"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+)
+
+// NewConfigCommand returns a new command to manage the settings file.
+func NewConfigCommand(cfg *command.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config <command>",
+		Short: "Manage default settings, model aliases and profiles",
+		Long: heredoc.Docf(`
+			Manages the settings file, which is stored in the gh config directory.
+
+			Settings are addressed by key:
+
+			- %[1]smodel%[1]s: the model to run when none is given
+			- %[1]sprovider%[1]s: the provider of models named without a %[1]s<provider>:%[1]s prefix
+			- %[1]sfallback%[1]s: comma-separated models to retry a request on when the model is rate limited or
+			  unavailable
+			- %[1]ssystem-prompt%[1]s: the system prompt to use
+			- %[1]sparameters.<name>%[1]s: a model parameter, such as %[1]sparameters.temperature%[1]s
+			- %[1]saliases.<alias>%[1]s: a short name for a model, such as %[1]saliases.fast%[1]s
+			- %[1]sprofiles.<profile>.<key>%[1]s: a setting that only applies with %[1]s--profile <profile>%[1]s
+			- %[1]shosts.<hostname>.inference-url%[1]s and %[1]shosts.<hostname>.catalog-url%[1]s: the GitHub Models
+			  API URLs for a GitHub host
+			- %[1]sproviders.<provider>.base-url%[1]s, %[1]sproviders.<provider>.api-key-env%[1]s and
+			  %[1]sproviders.<provider>.auth-header%[1]s: an OpenAI-compatible provider, and the environment
+			  variable and header for its API key
+			- %[1]sbudgets.<tier>.requests-per-day%[1]s and %[1]sbudgets.<tier>.tokens-per-day%[1]s: the daily budget
+			  of a rate limit tier, as shown by %[1]sgh models usage%[1]s. 0 means no limit
+			- %[1]ssort%[1]s: how models are sorted in %[1]sgh models list%[1]s and when selecting a model: %[1]sname%[1]s,
+			  %[1]spopularity%[1]s, %[1]spublisher%[1]s or %[1]scontext%[1]s
+			- %[1]spinned%[1]s and %[1]sfeatured%[1]s: comma-separated models to show first, pinned models before
+			  featured ones. %[1]sGH_MODELS_FEATURED%[1]s overrides %[1]sfeatured%[1]s, so an organisation can set it for
+			  everyone
+			- %[1]sprefer%[1]s: comma-separated models that %[1]sgh models run --auto%[1]s selects first, in order
+
+			Flags take precedence over environment variables such as %[1]sGH_MODELS_MODEL%[1]s or
+			%[1]sGH_MODELS_TEMPERATURE%[1]s, which take precedence over the selected profile, which takes
+			precedence over the defaults.
+		`, "`"),
+		Example: heredoc.Doc(`
+			gh models config set aliases.fast gpt-4o-mini
+			gh models config set model fast
+			gh models config set profiles.triage.parameters.temperature 0
+		`),
+		Annotations: map[string]string{
+			command.AnnotationSkipClient: "true",
+		},
+	}
+
+	cmd.AddCommand(newGetCommand(cfg))
+	cmd.AddCommand(newSetCommand(cfg))
+	cmd.AddCommand(newUnsetCommand(cfg))
+	cmd.AddCommand(newListCommand(cfg))
+
+	return cmd
+}
+
+func newGetCommand(cfg *command.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value, ok, err := cfg.Settings.Get(args[0])
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("'%s' is not set", args[0])
+			}
+			cfg.WriteToOut(value + "\n")
+			return nil
+		},
+	}
+}
+
+func newSetCommand(cfg *command.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Update a setting",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+
+			if name, ok := settings.ParameterName(key); ok {
+				mp := run.ModelParameters{}
+				err := mp.SetParameterByName(name, value)
+				if err != nil {
+					return err
+				}
+			}
+
+			err := cfg.Settings.Set(key, value)
+			if err != nil {
+				return err
+			}
+			return cfg.Settings.Save()
+		},
+	}
+}
+
+func newUnsetCommand(cfg *command.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cfg.Settings.Set(args[0], "")
+			if err != nil {
+				return err
+			}
+			return cfg.Settings.Save()
+		},
+	}
+}
+
+func newListCommand(cfg *command.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Print all settings",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, setting := range cfg.Settings.List() {
+				cfg.WriteToOut(setting[0] + "=" + setting[1] + "\n")
+			}
+			return nil
+		},
+	}
+}
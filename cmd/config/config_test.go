@@ -0,0 +1,80 @@
+package config
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/settings"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	newConfig := func(t *testing.T) (*command.Config, *bytes.Buffer, string) {
+		path := filepath.Join(t.TempDir(), "models.yml")
+		s, err := settings.LoadFile(path)
+		require.NoError(t, err)
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, azuremodels.NewMockClient(), false, 80)
+		cfg.Settings = s
+		return cfg, buf, path
+	}
+
+	t.Run("set saves the setting and get prints it", func(t *testing.T) {
+		cfg, buf, path := newConfig(t)
+
+		cmd := NewConfigCommand(cfg)
+		cmd.SetArgs([]string{"set", "profiles.triage.parameters.temperature", "0"})
+		_, err := cmd.ExecuteC()
+		require.NoError(t, err)
+
+		saved, err := settings.LoadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, [][2]string{{"profiles.triage.parameters.temperature", "0"}}, saved.List())
+
+		cmd = NewConfigCommand(cfg)
+		cmd.SetArgs([]string{"get", "profiles.triage.parameters.temperature"})
+		_, err = cmd.ExecuteC()
+		require.NoError(t, err)
+		require.Equal(t, "0\n", buf.String())
+	})
+
+	t.Run("set validates model parameters", func(t *testing.T) {
+		cfg, _, _ := newConfig(t)
+
+		cmd := NewConfigCommand(cfg)
+		cmd.SetArgs([]string{"set", "parameters.temperature", "hot"})
+		_, err := cmd.ExecuteC()
+
+		require.EqualError(t, err, "'hot' is not a number")
+	})
+
+	t.Run("list prints every setting and unset removes one", func(t *testing.T) {
+		cfg, buf, _ := newConfig(t)
+		require.NoError(t, cfg.Settings.Set("model", "fast"))
+		require.NoError(t, cfg.Settings.Set("aliases.fast", "gpt-4o-mini"))
+
+		cmd := NewConfigCommand(cfg)
+		cmd.SetArgs([]string{"unset", "model"})
+		_, err := cmd.ExecuteC()
+		require.NoError(t, err)
+
+		cmd = NewConfigCommand(cfg)
+		cmd.SetArgs([]string{"list"})
+		_, err = cmd.ExecuteC()
+		require.NoError(t, err)
+		require.Equal(t, "aliases.fast=gpt-4o-mini\n", buf.String())
+	})
+
+	t.Run("get fails for settings that aren't set", func(t *testing.T) {
+		cfg, _, _ := newConfig(t)
+
+		cmd := NewConfigCommand(cfg)
+		cmd.SetArgs([]string{"get", "model"})
+		_, err := cmd.ExecuteC()
+
+		require.EqualError(t, err, "'model' is not set")
+	})
+}
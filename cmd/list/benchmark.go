@@ -0,0 +1,64 @@
+package list
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/catalog"
+	"github.com/github/gh-models/pkg/command"
+)
+
+// rankedModel is a model with its score in the benchmark the models are ranked by.
+type rankedModel struct {
+	model     *catalog.Model
+	benchmark azuremodels.Benchmark
+}
+
+// listByBenchmark lists the chat models with a score in the given benchmark, highest score first.
+func listByBenchmark(ctx context.Context, cfg *command.Config, name string) error {
+	models, err := cfg.NewCatalogLoader().Load(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	ranked := []rankedModel{}
+	for _, model := range models.ChatModels() {
+		if model.Details == nil {
+			continue
+		}
+		if benchmark, ok := model.Details.Benchmark(name); ok {
+			ranked = append(ranked, rankedModel{model: model, benchmark: benchmark})
+		}
+	}
+	if len(ranked) == 0 {
+		return fmt.Errorf("no models have a score in the %s benchmark. Run 'gh models view --benchmarks [model]' to see the benchmarks of a model", name)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].benchmark.Score > ranked[j].benchmark.Score
+	})
+
+	if cfg.IsTerminalOutput {
+		cfg.WriteToOut("\n")
+		cfg.WriteToOut(fmt.Sprintf("Showing %d chat models ranked by %s\n", len(ranked), ranked[0].benchmark.Name))
+		cfg.WriteToOut("\n")
+	}
+
+	printer := cfg.NewTablePrinter()
+
+	printer.AddHeader([]string{"DISPLAY NAME", "MODEL NAME", "SCORE", "METRIC"}, tableprinter.WithColor(lightGrayUnderline))
+	printer.EndRow()
+
+	for _, r := range ranked {
+		printer.AddField(r.model.Summary.FriendlyName)
+		printer.AddField(r.model.Summary.Name)
+		printer.AddField(r.benchmark.FormatScore())
+		printer.AddField(r.benchmark.Metric)
+		printer.EndRow()
+	}
+
+	return printer.Render()
+}
@@ -14,6 +14,8 @@ import (
 
 var (
 	lightGrayUnderline = ansi.ColorFunc("white+du")
+	olderVersionColor  = ansi.ColorFunc("yellow")
+	deprecatedColor    = ansi.ColorFunc("red")
 )
 
 // NewListCommand returns a new command to list available GitHub models.
@@ -26,12 +28,48 @@ func NewListCommand(cfg *command.Config) *cobra.Command {
 
 			Values from the "MODEL NAME" column can be used as the %[1]s[model]%[1]s
 			argument in other commands.
+
+			With %[1]s--all-versions%[1]s, every version of each model is listed as %[1]s<model>@<version>%[1]s,
+			which pins that version in other commands. Only the latest version of a model runs, so pinned
+			older versions can be viewed, but not run.
+
+			With %[1]s--benchmark <name>%[1]s, the models are ranked by their score in that benchmark, as
+			reported in their evaluations. Models without a score are left out. Run
+			%[1]sgh models view --benchmarks [model]%[1]s to see the benchmarks of a model.
+
+			Models are sorted by %[1]s--sort%[1]s, or the %[1]ssort%[1]s setting: %[1]sname%[1]s, %[1]spopularity%[1]s,
+			%[1]spublisher%[1]s, or %[1]scontext%[1]s for the largest context first. The models in the %[1]spinned%[1]s
+			and %[1]sfeatured%[1]s settings are listed first.
 		`, "`"),
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			client := cfg.Client
-			models, err := client.ListModels(ctx)
+
+			allVersions, err := cmd.Flags().GetBool("all-versions")
+			if err != nil {
+				return err
+			}
+
+			sortOrder, err := cmd.Flags().GetString("sort")
+			if err != nil {
+				return err
+			}
+
+			benchmark, err := cmd.Flags().GetString("benchmark")
+			if err != nil {
+				return err
+			}
+			if benchmark != "" {
+				return listByBenchmark(cmd.Context(), cfg, benchmark)
+			}
+
+			var models []*azuremodels.ModelSummary
+			if allVersions {
+				models, err = client.ListModelVersions(ctx)
+			} else {
+				models, err = client.ListModels(ctx)
+			}
 			if err != nil {
 				return err
 			}
@@ -39,7 +77,9 @@ func NewListCommand(cfg *command.Config) *cobra.Command {
 			// For now, filter to just chat models.
 			// Once other tasks are supported (like embeddings), update the list to show all models, with the task as a column.
 			models = filterToChatModels(models)
-			azuremodels.SortModels(models)
+			if err := cfg.SortModels(ctx, models, sortOrder); err != nil {
+				return err
+			}
 
 			if cfg.IsTerminalOutput {
 				cfg.WriteToOut("\n")
@@ -49,12 +89,27 @@ func NewListCommand(cfg *command.Config) *cobra.Command {
 
 			printer := cfg.NewTablePrinter()
 
-			printer.AddHeader([]string{"DISPLAY NAME", "MODEL NAME"}, tableprinter.WithColor(lightGrayUnderline))
+			if allVersions {
+				printer.AddHeader([]string{"DISPLAY NAME", "MODEL NAME", "STATUS"}, tableprinter.WithColor(lightGrayUnderline))
+			} else {
+				printer.AddHeader([]string{"DISPLAY NAME", "MODEL NAME"}, tableprinter.WithColor(lightGrayUnderline))
+			}
 			printer.EndRow()
 
 			for _, model := range models {
-				printer.AddField(model.FriendlyName)
-				printer.AddField(model.Name)
+				if allVersions {
+					printer.AddField(model.FriendlyName)
+					printer.AddField(model.VersionedName())
+					status, color := versionStatus(model)
+					printer.AddField(status, tableprinter.WithColor(color))
+				} else {
+					friendlyName := model.FriendlyName
+					if model.IsDeprecated() {
+						friendlyName += " (deprecated)"
+					}
+					printer.AddField(friendlyName)
+					printer.AddField(model.Name)
+				}
 				printer.EndRow()
 			}
 
@@ -67,9 +122,28 @@ func NewListCommand(cfg *command.Config) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().Bool("all-versions", false, "List every version of each model, instead of only the latest.")
+	cmd.Flags().String("benchmark", "", "Rank the models by their score in this benchmark.")
+	cmd.Flags().String("sort", "", "Sort the models by name, popularity, publisher or context.")
+	cmd.MarkFlagsMutuallyExclusive("all-versions", "benchmark")
+	cmd.MarkFlagsMutuallyExclusive("sort", "benchmark")
+
 	return cmd
 }
 
+// versionStatus describes whether the model version is the latest one, which runs, and whether it's deprecated, with
+// the colour to show it in.
+func versionStatus(model *azuremodels.ModelSummary) (string, func(string) string) {
+	status, color := "latest", func(s string) string { return s }
+	if !model.IsLatest() {
+		status, color = "older version", olderVersionColor
+	}
+	if model.IsDeprecated() {
+		status, color = status+", deprecated", deprecatedColor
+	}
+	return status, color
+}
+
 func filterToChatModels(models []*azuremodels.ModelSummary) []*azuremodels.ModelSummary {
 	var chatModels []*azuremodels.ModelSummary
 	for _, model := range models {
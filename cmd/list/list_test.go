@@ -44,6 +44,89 @@ func TestList(t *testing.T) {
 		require.Contains(t, output, modelSummary.Name)
 	})
 
+	t.Run("--all-versions labels each version", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		client.MockListModelVersions = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{
+				{Name: "gpt-4o", FriendlyName: "GPT-4o", Task: "chat-completion", Version: "2024-05-13", Labels: []string{"deprecated"}},
+				{Name: "gpt-4o", FriendlyName: "GPT-4o", Task: "chat-completion", Version: "2024-08-06", Labels: []string{"latest"}},
+			}, nil
+		}
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, client, false, 80)
+		listCmd := NewListCommand(cfg)
+		listCmd.SetArgs([]string{"--all-versions"})
+
+		_, err := listCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "GPT-4o\tgpt-4o@2024-08-06\tlatest\nGPT-4o\tgpt-4o@2024-05-13\tolder version, deprecated\n")
+	})
+
+	t.Run("--benchmark ranks the models by their score", func(t *testing.T) {
+		t.Setenv("XDG_CACHE_HOME", t.TempDir())
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{
+				{Name: "phi-4", FriendlyName: "Phi-4", Task: "chat-completion"},
+				{Name: "gpt-4o", FriendlyName: "GPT-4o", Task: "chat-completion"},
+				{Name: "unscored", FriendlyName: "Unscored", Task: "chat-completion"},
+			}, nil
+		}
+		scores := map[string]float64{"phi-4": 84.8, "gpt-4o": 88.7}
+		client.MockGetModelDetails = func(ctx context.Context, registry, modelName, version string) (*azuremodels.ModelDetails, error) {
+			score, ok := scores[modelName]
+			if !ok {
+				return &azuremodels.ModelDetails{}, nil
+			}
+			return &azuremodels.ModelDetails{Benchmarks: []azuremodels.Benchmark{{Name: "MMLU", Score: score}}}, nil
+		}
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, client, false, 80)
+		listCmd := NewListCommand(cfg)
+		listCmd.SetArgs([]string{"--benchmark", "mmlu"})
+
+		_, err := listCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "\nGPT-4o\tgpt-4o\t88.7\t\nPhi-4\tphi-4\t84.8\t\n", buf.String())
+	})
+
+	t.Run("--sort orders the models, after the pinned models", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{
+				{Name: "phi-4", FriendlyName: "Phi-4", Task: "chat-completion", Popularity: 3},
+				{Name: "gpt-4o", FriendlyName: "GPT-4o", Task: "chat-completion", Popularity: 9},
+				{Name: "llama", FriendlyName: "Llama", Task: "chat-completion", Popularity: 5},
+			}, nil
+		}
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, client, false, 80)
+		cfg.Settings.Pinned = []string{"phi-4"}
+		listCmd := NewListCommand(cfg)
+		listCmd.SetArgs([]string{"--sort", "popularity"})
+
+		_, err := listCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "\nPhi-4\tphi-4\nGPT-4o\tgpt-4o\nLlama\tllama\n", buf.String())
+	})
+
+	t.Run("--sort rejects unknown orders", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{}, nil
+		}
+		buf := new(bytes.Buffer)
+		listCmd := NewListCommand(command.NewConfig(buf, buf, client, false, 80))
+		listCmd.SetArgs([]string{"--sort", "size"})
+
+		_, err := listCmd.ExecuteC()
+
+		require.EqualError(t, err, "invalid sort order 'size'. Use one of: name, popularity, publisher, context")
+	})
+
 	t.Run("--help prints usage info", func(t *testing.T) {
 		outBuf := new(bytes.Buffer)
 		errBuf := new(bytes.Buffer)
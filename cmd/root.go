@@ -3,14 +3,23 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
+	"os"
 	"strings"
 
-	"github.com/cli/go-gh/v2/pkg/auth"
 	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/github/gh-models/cmd/auth"
+	"github.com/github/gh-models/cmd/catalog"
+	"github.com/github/gh-models/cmd/compare"
+	"github.com/github/gh-models/cmd/config"
 	"github.com/github/gh-models/cmd/list"
 	"github.com/github/gh-models/cmd/run"
+	"github.com/github/gh-models/cmd/search"
+	"github.com/github/gh-models/cmd/usage"
 	"github.com/github/gh-models/cmd/view"
 	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/quota"
+	"github.com/github/gh-models/internal/settings"
 	"github.com/github/gh-models/pkg/command"
 	"github.com/github/gh-models/pkg/util"
 	"github.com/MakeNowJust/heredoc"
@@ -35,27 +44,56 @@ func NewRootCommand() *cobra.Command {
 	}
 
 	terminal := term.FromEnv()
-	out := terminal.Out()
-	token, _ := auth.TokenForHost("github.com")
+	cfg := command.NewConfigWithTerminal(terminal, nil)
 
-	var client azuremodels.Client
-
-	if token == "" {
-		util.WriteToOut(out, "No GitHub token found. Please run 'gh auth login' to authenticate.\n")
-		client = azuremodels.NewUnauthenticatedClient()
+	userSettings, err := settings.Load()
+	if err != nil {
+		// Carry on with empty settings, which can't be saved, so the broken file isn't overwritten.
+		util.WriteToOut(terminal.ErrOut(), "Error loading settings: "+err.Error()+"\n")
 	} else {
-		var err error
-		client, err = azuremodels.NewDefaultAzureClient(token)
+		cfg.Settings = userSettings
+	}
+
+	cmd.PersistentFlags().String("hostname", "", "The GitHub host to use, instead of GH_HOST or the default host.")
+
+	// The client is created once the flags are parsed, since it depends on the host.
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		hostname, err := cmd.Flags().GetString("hostname")
 		if err != nil {
-			util.WriteToOut(terminal.ErrOut(), "Error creating Azure client: "+err.Error())
+			return err
+		}
+		cfg.Auth = command.ResolveAuth(hostname, cfg.Settings)
+
+		if skipClient(cmd) {
 			return nil
 		}
-	}
 
-	cfg := command.NewConfigWithTerminal(terminal, client)
+		if cfg.Auth.Endpoints == nil {
+			return fmt.Errorf("the GitHub Models API URLs for %[1]s are not known. Set them with 'gh models config set hosts.%[1]s.inference-url <url>' and 'gh models config set hosts.%[1]s.catalog-url <url>'", cfg.Auth.Host)
+		}
+
+		if cfg.Auth.Token == "" {
+			// The catalog is public, so models can be listed and viewed before authenticating.
+			cfg.Client = withProviders(azuremodels.NewUnauthenticatedClient(cfg.Auth.Host, cfg.Auth.Endpoints), cfg)
+			return nil
+		}
 
+		client, err := azuremodels.NewDefaultAzureClientWithConfig(cfg.Auth.Token, cfg.Auth.Endpoints)
+		if err != nil {
+			return fmt.Errorf("error creating Azure client: %w", err)
+		}
+		cfg.Client = withProviders(withBudgets(client, cfg), cfg)
+		return nil
+	}
+
+	cmd.AddCommand(auth.NewAuthCommand(cfg))
+	cmd.AddCommand(catalog.NewCatalogCommand(cfg))
+	cmd.AddCommand(compare.NewCompareCommand(cfg))
+	cmd.AddCommand(config.NewConfigCommand(cfg))
 	cmd.AddCommand(list.NewListCommand(cfg))
 	cmd.AddCommand(run.NewRunCommand(cfg))
+	cmd.AddCommand(search.NewSearchCommand(cfg))
+	cmd.AddCommand(usage.NewUsageCommand(cfg))
 	cmd.AddCommand(view.NewViewCommand(cfg))
 
 	// Cobra does not have a nice way to inject "global" help text, so we have to do it manually.
@@ -73,3 +111,46 @@ func NewRootCommand() *cobra.Command {
 		"{{.CommandPath}}", "gh {{.CommandPath}}").Replace(cmd.UsageTemplate()))
 	return cmd
 }
+
+// skipClient returns true if the command, or one of its parents, works without a models client.
+func skipClient(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[command.AnnotationSkipClient] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// withProviders returns a client that also serves the models of the providers in the settings, if there are any.
+// Providers whose models can't be listed are skipped with a warning.
+func withProviders(client azuremodels.Client, cfg *command.Config) azuremodels.Client {
+	if len(cfg.Settings.Providers) == 0 {
+		return client
+	}
+
+	providers := make(map[string]azuremodels.Client, len(cfg.Settings.Providers))
+	for name, provider := range cfg.Settings.Providers {
+		apiKey := ""
+		if provider.APIKeyEnv != "" {
+			apiKey = os.Getenv(provider.APIKeyEnv)
+		}
+		providers[name] = azuremodels.NewOpenAIClient(http.DefaultClient, &azuremodels.OpenAIClientConfig{
+			Name:       name,
+			BaseURL:    provider.BaseURL,
+			APIKey:     apiKey,
+			AuthHeader: provider.AuthHeader,
+		})
+	}
+	return azuremodels.NewProviderClient(client, providers, func(provider string, err error) {
+		util.WriteToOut(cfg.ErrOut, fmt.Sprintf("Warning: skipping the models of the %s provider, which couldn't be listed: %v\n", provider, err))
+	})
+}
+
+// withBudgets returns a client that checks chat completions against the daily budget of the model's rate limit tier.
+// Models whose tier is unknown aren't limited.
+func withBudgets(client azuremodels.Client, cfg *command.Config) azuremodels.Client {
+	return quota.NewClient(client, cfg.NewQuotaTracker(), func(message string) {
+		util.WriteToOut(cfg.ErrOut, message)
+	})
+}
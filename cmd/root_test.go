@@ -19,8 +19,14 @@ func TestRoot(t *testing.T) {
 		require.NoError(t, err)
 		output := buf.String()
 		require.Regexp(t, regexp.MustCompile(`Usage:\n\s+gh models \[command\]`), output)
+		require.Regexp(t, regexp.MustCompile(`auth\s+Inspect authentication with GitHub`), output)
+		require.Regexp(t, regexp.MustCompile(`catalog\s+Track changes to the available models`), output)
+		require.Regexp(t, regexp.MustCompile(`compare\s+Compare the responses of several models to a prompt`), output)
+		require.Regexp(t, regexp.MustCompile(`config\s+Manage default settings, model aliases and profiles`), output)
 		require.Regexp(t, regexp.MustCompile(`list\s+List available models`), output)
 		require.Regexp(t, regexp.MustCompile(`run\s+Run inference with the specified model`), output)
+		require.Regexp(t, regexp.MustCompile(`search\s+Search the available models`), output)
+		require.Regexp(t, regexp.MustCompile(`usage\s+Show the remaining daily budget of each rate limit tier`), output)
 		require.Regexp(t, regexp.MustCompile(`view\s+View details about a model`), output)
 	})
 }
@@ -0,0 +1,59 @@
+package run
+
+import (
+	"fmt"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/catalog"
+	"github.com/github/gh-models/pkg/util"
+	"github.com/spf13/pflag"
+)
+
+// parseRequirements returns the requirements of the model to select automatically, or nil if the model isn't
+// selected automatically. --needs and --min-context imply --auto.
+func parseRequirements(flags *pflag.FlagSet, preferred []string) (*catalog.Requirements, error) {
+	auto, err := flags.GetBool("auto")
+	if err != nil {
+		return nil, err
+	}
+	needs, err := flags.GetStringSlice("needs")
+	if err != nil {
+		return nil, err
+	}
+	minContext, err := flags.GetInt("min-context")
+	if err != nil {
+		return nil, err
+	}
+	if !auto && len(needs) == 0 && minContext == 0 {
+		return nil, nil
+	}
+
+	requirements := &catalog.Requirements{Needs: needs, MinContext: minContext, Preferred: preferred}
+	if err := requirements.Validate(); err != nil {
+		return nil, err
+	}
+	return requirements, nil
+}
+
+// selectModel returns the name of the best model that meets the requirements, among the given models that can run,
+// and reports the choice and the reason for it on ErrOut.
+func (h *runCommandHandler) selectModel(models []*azuremodels.ModelSummary, requirements catalog.Requirements) (string, error) {
+	loaded, err := h.cfg.NewCatalogLoader().Load(h.ctx, false)
+	if err != nil {
+		return "", err
+	}
+
+	requirements.Available = []string{}
+	for _, model := range models {
+		requirements.Available = append(requirements.Available, model.Name)
+	}
+
+	selection, err := catalog.Select(loaded.Models, requirements)
+	if err != nil {
+		return "", err
+	}
+
+	util.WriteToOut(h.cfg.ErrOut, fmt.Sprintf("Selected %s from the models that %s: %s.\n",
+		selection.Model.Summary.Name, requirements.Describe(), selection.Reason))
+	return selection.Model.Summary.Name, nil
+}
@@ -0,0 +1,119 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// codeBlock is a fenced code block from a model's response.
+type codeBlock struct {
+	language string
+	code     string
+}
+
+// description returns a one-line description of the code block, for use in prompts.
+func (b codeBlock) description() string {
+	language := b.language
+	if language == "" {
+		language = "text"
+	}
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(b.code), "\n")
+	return fmt.Sprintf("%s: %s", language, firstLine)
+}
+
+// extractCodeBlocks returns the fenced code blocks in the given markdown, in order. A code block that is not closed, for
+// example because the response was truncated, runs to the end of the markdown.
+func extractCodeBlocks(markdown string) []codeBlock {
+	var blocks []codeBlock
+	var current *codeBlock
+	var code strings.Builder
+	fence := ""
+
+	for _, line := range strings.SplitAfter(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if current == nil {
+			marker := fenceMarker(trimmed)
+			if marker == "" {
+				continue
+			}
+			fence = marker
+			info := strings.TrimSpace(strings.TrimPrefix(trimmed, marker))
+			language, _, _ := strings.Cut(info, " ")
+			current = &codeBlock{language: strings.ToLower(language)}
+			code.Reset()
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			current.code = code.String()
+			blocks = append(blocks, *current)
+			current = nil
+			continue
+		}
+
+		code.WriteString(line)
+	}
+
+	if current != nil {
+		current.code = code.String()
+		blocks = append(blocks, *current)
+	}
+
+	return blocks
+}
+
+// fenceMarker returns the backtick or tilde fence that opens a code block on the given line, if any.
+func fenceMarker(line string) string {
+	for _, char := range []string{"`", "~"} {
+		if !strings.HasPrefix(line, strings.Repeat(char, 3)) {
+			continue
+		}
+		marker := line[:len(line)-len(strings.TrimLeft(line, char))]
+		// Backtick fences can't have backticks in their info string.
+		if char == "`" && strings.Contains(line[len(marker):], "`") {
+			return ""
+		}
+		return marker
+	}
+	return ""
+}
+
+// codeBlockSelector chooses which code blocks to output.
+type codeBlockSelector struct {
+	// index is the 1-based index of the block to select, or 0 to select all blocks.
+	index int
+	// language selects only blocks in this language, if set.
+	language string
+}
+
+// selectBlocks returns the code blocks matching the selector.
+func (s codeBlockSelector) selectBlocks(blocks []codeBlock) ([]codeBlock, error) {
+	if len(blocks) == 0 {
+		return nil, errors.New("the response does not contain any code blocks")
+	}
+
+	if s.language != "" {
+		var matching []codeBlock
+		for _, block := range blocks {
+			if strings.EqualFold(block.language, s.language) {
+				matching = append(matching, block)
+			}
+		}
+		if len(matching) == 0 {
+			return nil, fmt.Errorf("the response does not contain any %s code blocks", s.language)
+		}
+		blocks = matching
+	}
+
+	if s.index == 0 {
+		return blocks, nil
+	}
+
+	if s.index < 0 || s.index > len(blocks) {
+		return nil, fmt.Errorf("code block %d does not exist, the response contains %d matching code blocks", s.index, len(blocks))
+	}
+
+	return blocks[s.index-1 : s.index], nil
+}
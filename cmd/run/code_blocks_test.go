@@ -0,0 +1,56 @@
+package run
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeBlocks(t *testing.T) {
+	t.Run("extractCodeBlocks", func(t *testing.T) {
+		markdown := "Run this:\n\n```Bash\necho hi\n\necho bye\n```\n\nThen:\n\n~~~~\nplain text\n```\nstill plain\n~~~~\n\n```python title=\"x\"\nprint('unterminated')\n"
+
+		blocks := extractCodeBlocks(markdown)
+
+		require.Equal(t, []codeBlock{
+			{language: "bash", code: "echo hi\n\necho bye\n"},
+			{language: "", code: "plain text\n```\nstill plain\n"},
+			{language: "python", code: "print('unterminated')\n"},
+		}, blocks)
+	})
+
+	t.Run("extractCodeBlocks ignores inline code", func(t *testing.T) {
+		require.Empty(t, extractCodeBlocks("Use ```inline``` code sparingly."))
+	})
+
+	t.Run("selectBlocks", func(t *testing.T) {
+		blocks := []codeBlock{
+			{language: "go", code: "one"},
+			{language: "sh", code: "two"},
+			{language: "go", code: "three"},
+		}
+
+		selected, err := codeBlockSelector{}.selectBlocks(blocks)
+		require.NoError(t, err)
+		require.Len(t, selected, 3)
+
+		selected, err = codeBlockSelector{index: 2}.selectBlocks(blocks)
+		require.NoError(t, err)
+		require.Equal(t, []codeBlock{{language: "sh", code: "two"}}, selected)
+
+		selected, err = codeBlockSelector{index: 2, language: "GO"}.selectBlocks(blocks)
+		require.NoError(t, err)
+		require.Equal(t, []codeBlock{{language: "go", code: "three"}}, selected)
+
+		_, err = codeBlockSelector{index: 4}.selectBlocks(blocks)
+		require.EqualError(t, err, "code block 4 does not exist, the response contains 3 matching code blocks")
+
+		_, err = codeBlockSelector{language: "rust"}.selectBlocks(blocks)
+		require.EqualError(t, err, "the response does not contain any rust code blocks")
+	})
+
+	t.Run("description", func(t *testing.T) {
+		require.Equal(t, "go: package main", codeBlock{language: "go", code: "package main\n\nfunc main() {}\n"}.description())
+		require.Equal(t, "text: hello", codeBlock{code: "hello\n"}.description())
+	})
+}
@@ -0,0 +1,194 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/tokens"
+	"github.com/github/gh-models/pkg/util"
+)
+
+// contextStrategy controls what happens when the conversation no longer fits in the model's context window.
+type contextStrategy string
+
+const (
+	// contextStrategyDropOldest drops the oldest messages first, including the system prompt.
+	contextStrategyDropOldest contextStrategy = "drop-oldest"
+	// contextStrategyPinSystem drops the oldest turns first, but always keeps the system prompt.
+	contextStrategyPinSystem contextStrategy = "pin-system"
+	// contextStrategySummarize replaces the oldest turns with a summary written by the model.
+	contextStrategySummarize contextStrategy = "summarize"
+	// contextStrategyNone always sends the full history.
+	contextStrategyNone contextStrategy = "none"
+)
+
+var contextStrategies = []contextStrategy{
+	contextStrategyDropOldest,
+	contextStrategyPinSystem,
+	contextStrategySummarize,
+	contextStrategyNone,
+}
+
+func parseContextStrategy(value string) (contextStrategy, error) {
+	names := make([]string, len(contextStrategies))
+	for i, strategy := range contextStrategies {
+		if strings.EqualFold(value, string(strategy)) {
+			return strategy, nil
+		}
+		names[i] = string(strategy)
+	}
+	return "", fmt.Errorf("unknown context strategy '%s'. Supported strategies: %s", value, strings.Join(names, ", "))
+}
+
+// summarizer asks the model to summarize the given messages.
+type summarizer func(messages []azuremodels.ChatMessage) (string, error)
+
+const summaryPrompt = "Summarize the conversation so far in a few short paragraphs. " +
+	"Keep any facts, decisions, code, names and open questions that later messages may depend on."
+
+// contextWindow keeps a conversation within the input token limit of a model.
+type contextWindow struct {
+	maxInputTokens int
+	strategy       contextStrategy
+}
+
+// hasLimit returns true if the model's input token limit is known.
+func (w *contextWindow) hasLimit() bool {
+	return w.maxInputTokens > 0
+}
+
+// fit removes or summarizes older messages in the conversation until it fits in the context window, and returns the
+// number of messages that were removed.
+func (w *contextWindow) fit(conversation *Conversation, summarize summarizer) (int, error) {
+	if !w.hasLimit() || w.strategy == contextStrategyNone {
+		return 0, nil
+	}
+
+	if tokens.EstimateMessages(conversation.GetMessages()) <= w.maxInputTokens {
+		return 0, nil
+	}
+
+	removed := 0
+	if w.strategy == contextStrategySummarize && summarize != nil {
+		var err error
+		removed, err = w.summarizeOldest(conversation, summarize)
+		if err != nil {
+			return removed, err
+		}
+	}
+
+	for tokens.EstimateMessages(conversation.GetMessages()) > w.maxInputTokens {
+		if w.strategy == contextStrategyDropOldest && conversation.systemPrompt != "" {
+			conversation.systemPrompt = ""
+			removed++
+			continue
+		}
+
+		// Always keep the latest message, which is the prompt we are about to send.
+		if len(conversation.messages) <= 1 {
+			return removed, fmt.Errorf("the prompt is about %d tokens, which exceeds the %d input tokens supported by this model",
+				tokens.EstimateMessages(conversation.GetMessages()), w.maxInputTokens)
+		}
+
+		removed += conversation.dropOldestTurn()
+	}
+
+	return removed, nil
+}
+
+// summarizeOldest replaces the older half of the conversation history with a summary of it.
+func (w *contextWindow) summarizeOldest(conversation *Conversation, summarize summarizer) (int, error) {
+	// Keep the most recent turns verbatim, using at most half the window for them.
+	keepBudget := w.maxInputTokens / 2
+	keep := 0
+	used := 0
+	for i := len(conversation.messages) - 1; i >= 0; i-- {
+		used += tokens.EstimateMessage(conversation.messages[i])
+		if used > keepBudget && keep > 0 {
+			break
+		}
+		keep++
+	}
+
+	older := conversation.messages[:len(conversation.messages)-keep]
+	if len(older) == 0 {
+		return 0, nil
+	}
+
+	// The summarization request has to fit in the window too, so the older messages are summarized in chunks that fit,
+	// each with the summary of the chunks before it.
+	prompt := azuremodels.ChatMessage{Role: azuremodels.ChatMessageRoleUser, Content: util.Ptr(summaryPrompt)}
+	var summary *azuremodels.ChatMessage
+	for start := 0; start < len(older); {
+		request := []azuremodels.ChatMessage{}
+		if summary != nil {
+			request = append(request, *summary)
+		}
+		used := tokens.EstimateMessages(append(request, prompt))
+
+		end := start
+		for ; end < len(older); end++ {
+			size := tokens.EstimateMessage(older[end])
+			if used+size > w.maxInputTokens {
+				break
+			}
+			request = append(request, older[end])
+			used += size
+		}
+		if end == start {
+			// A message too long to summarize on its own is cut to fit.
+			request = append(request, truncateMessage(older[start], w.maxInputTokens-used))
+			end++
+		}
+		request = append(request, prompt)
+
+		text, err := summarize(request)
+		if err != nil {
+			return 0, errors.New("unable to summarize the conversation: " + err.Error())
+		}
+		summary = &azuremodels.ChatMessage{
+			Role:    azuremodels.ChatMessageRoleUser,
+			Content: util.Ptr("Summary of the earlier conversation:\n" + strings.TrimSpace(text)),
+		}
+		start = end
+	}
+
+	conversation.messages = append([]azuremodels.ChatMessage{*summary}, conversation.messages[len(older):]...)
+
+	return len(older), nil
+}
+
+// truncateMessage returns the message with its content cut, at a word boundary, to fit in about the given number of
+// tokens.
+func truncateMessage(message azuremodels.ChatMessage, limit int) azuremodels.ChatMessage {
+	if message.Content == nil {
+		return message
+	}
+	limit -= tokens.EstimateMessage(azuremodels.ChatMessage{Role: message.Role})
+	kept := []string{}
+	used := 0
+	for _, word := range strings.Fields(*message.Content) {
+		size := tokens.Estimate(word)
+		if used+size > limit {
+			break
+		}
+		kept = append(kept, word)
+		used += size
+	}
+	message.Content = util.Ptr(strings.Join(kept, " "))
+	return message
+}
+
+// usage returns a summary of how much of the context window the conversation uses.
+func (w *contextWindow) usage(conversation *Conversation) string {
+	messages := conversation.GetMessages()
+	used := tokens.EstimateMessages(messages)
+	summary := fmt.Sprintf("~%d tokens across %d messages", used, len(messages))
+	if !w.hasLimit() {
+		return summary + " (the model's input limit is unknown)"
+	}
+	percent := float64(used) / float64(w.maxInputTokens) * 100
+	return fmt.Sprintf("%s, %.1f%% of the %d input tokens supported by this model", summary, percent, w.maxInputTokens)
+}
@@ -0,0 +1,131 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/tokens"
+	"github.com/github/gh-models/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConversation(systemPrompt string, turns int) *Conversation {
+	conversation := &Conversation{systemPrompt: systemPrompt}
+	for i := 0; i < turns; i++ {
+		conversation.AddMessage(azuremodels.ChatMessageRoleUser, strings.Repeat("word ", 20))
+		conversation.AddMessage(azuremodels.ChatMessageRoleAssistant, strings.Repeat("reply ", 20))
+	}
+	conversation.AddMessage(azuremodels.ChatMessageRoleUser, "latest prompt")
+	return conversation
+}
+
+func TestContextWindow(t *testing.T) {
+	t.Run("does nothing when the limit is unknown", func(t *testing.T) {
+		conversation := newTestConversation("be brief", 10)
+		window := contextWindow{strategy: contextStrategyPinSystem}
+
+		removed, err := window.fit(conversation, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, 0, removed)
+		require.Len(t, conversation.messages, 21)
+	})
+
+	t.Run("pin-system drops the oldest turns and keeps the system prompt", func(t *testing.T) {
+		conversation := newTestConversation("be brief", 10)
+		window := contextWindow{maxInputTokens: 50, strategy: contextStrategyPinSystem}
+
+		removed, err := window.fit(conversation, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, 20, removed)
+		require.Equal(t, "be brief", conversation.systemPrompt)
+		require.Len(t, conversation.messages, 1)
+		require.Equal(t, "latest prompt", *conversation.messages[0].Content)
+	})
+
+	t.Run("drop-oldest drops the system prompt first", func(t *testing.T) {
+		conversation := newTestConversation(strings.Repeat("rule ", 100), 1)
+		window := contextWindow{maxInputTokens: 100, strategy: contextStrategyDropOldest}
+
+		removed, err := window.fit(conversation, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, 1, removed)
+		require.Empty(t, conversation.systemPrompt)
+		require.Len(t, conversation.messages, 3)
+	})
+
+	t.Run("summarize replaces older turns with a summary", func(t *testing.T) {
+		conversation := newTestConversation("", 10)
+		window := contextWindow{maxInputTokens: 200, strategy: contextStrategySummarize}
+		var summarized []azuremodels.ChatMessage
+
+		removed, err := window.fit(conversation, func(messages []azuremodels.ChatMessage) (string, error) {
+			summarized = messages
+			return "we talked about words", nil
+		})
+
+		require.NoError(t, err)
+		require.Greater(t, removed, 0)
+		require.Equal(t, summaryPrompt, *summarized[len(summarized)-1].Content)
+		require.Equal(t, "Summary of the earlier conversation:\nwe talked about words", *conversation.messages[0].Content)
+		require.Equal(t, "latest prompt", *conversation.messages[len(conversation.messages)-1].Content)
+	})
+
+	t.Run("summarize keeps each summarization request within the window", func(t *testing.T) {
+		conversation := newTestConversation("", 40)
+		conversation.messages[0].Content = util.Ptr(strings.Repeat("long ", 500))
+		window := contextWindow{maxInputTokens: 120, strategy: contextStrategySummarize}
+		requests := 0
+
+		removed, err := window.fit(conversation, func(messages []azuremodels.ChatMessage) (string, error) {
+			requests++
+			require.LessOrEqual(t, tokens.EstimateMessages(messages), window.maxInputTokens)
+			require.Equal(t, summaryPrompt, *messages[len(messages)-1].Content)
+			if requests > 1 {
+				require.Equal(t, fmt.Sprintf("Summary of the earlier conversation:\nsummary %d", requests-1), *messages[0].Content)
+			}
+			return fmt.Sprintf("summary %d", requests), nil
+		})
+
+		require.NoError(t, err)
+		require.Greater(t, requests, 1)
+		require.Greater(t, removed, 0)
+		require.Equal(t, fmt.Sprintf("Summary of the earlier conversation:\nsummary %d", requests), *conversation.messages[0].Content)
+		require.LessOrEqual(t, tokens.EstimateMessages(conversation.GetMessages()), window.maxInputTokens)
+	})
+
+	t.Run("summarize reports errors from the model", func(t *testing.T) {
+		conversation := newTestConversation("", 10)
+		window := contextWindow{maxInputTokens: 200, strategy: contextStrategySummarize}
+
+		_, err := window.fit(conversation, func(messages []azuremodels.ChatMessage) (string, error) {
+			return "", errors.New("o noes")
+		})
+
+		require.EqualError(t, err, "unable to summarize the conversation: o noes")
+	})
+
+	t.Run("errors when the latest prompt alone is too long", func(t *testing.T) {
+		conversation := &Conversation{}
+		conversation.AddMessage(azuremodels.ChatMessageRoleUser, strings.Repeat("word ", 100))
+		window := contextWindow{maxInputTokens: 50, strategy: contextStrategyPinSystem}
+
+		_, err := window.fit(conversation, nil)
+
+		require.ErrorContains(t, err, "exceeds the 50 input tokens supported by this model")
+	})
+
+	t.Run("parseContextStrategy", func(t *testing.T) {
+		strategy, err := parseContextStrategy("Summarize")
+		require.NoError(t, err)
+		require.Equal(t, contextStrategySummarize, strategy)
+
+		_, err = parseContextStrategy("forget-everything")
+		require.EqualError(t, err, "unknown context strategy 'forget-everything'. Supported strategies: drop-oldest, pin-system, summarize, none")
+	})
+}
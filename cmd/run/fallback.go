@@ -0,0 +1,60 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/quota"
+	"github.com/github/gh-models/internal/settings"
+	"github.com/github/gh-models/pkg/util"
+	"github.com/spf13/pflag"
+)
+
+// setFallback makes requests fall back to the models given by --fallback, or by the profile's fallback setting, when
+// the model is rate limited or unavailable.
+func (h *runCommandHandler) setFallback(flags *pflag.FlagSet, models []*azuremodels.ModelSummary, profile settings.Profile) error {
+	fallback := profile.Fallback
+	if flags.Changed("fallback") {
+		names, err := flags.GetStringSlice("fallback")
+		if err != nil {
+			return err
+		}
+		fallback = make([]string, len(names))
+		for i, name := range names {
+			fallback[i] = h.cfg.Settings.ResolveModel(name, profile)
+		}
+	}
+	if len(fallback) == 0 {
+		return nil
+	}
+
+	for i, name := range fallback {
+		if err := azuremodels.CheckProvider(h.cfg.Client, name); err != nil {
+			return fmt.Errorf("invalid fallback model '%s': %w", name, err)
+		}
+		model, err := validateModelName(name, models)
+		if err != nil {
+			return fmt.Errorf("invalid fallback model '%s': %w", name, err)
+		}
+		fallback[i] = model.Name
+	}
+
+	h.fallback = fallback
+	h.client = azuremodels.NewFallbackClient(h.client, fallback, h.reportFallback)
+	return nil
+}
+
+// reportFallback warns that a request is retried on the next fallback model.
+func (h *runCommandHandler) reportFallback(from, to string, err error) {
+	reason := "failed with " + err.Error()
+	var httpErr *azuremodels.HTTPError
+	var budgetErr *quota.BudgetExceededError
+	switch {
+	case errors.As(err, &httpErr):
+		reason = "failed with " + httpErr.Status
+	case errors.As(err, &budgetErr):
+		reason = fmt.Sprintf("would exceed the daily budget of %d %s for the %s rate limit tier", budgetErr.Limit, budgetErr.Unit, budgetErr.Tier)
+	}
+	util.WriteToOut(h.cfg.ErrOut, fmt.Sprintf("Warning: %s %s, retrying on %s.\n", from, reason, to))
+}
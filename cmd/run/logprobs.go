@@ -0,0 +1,132 @@
+package run
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/pkg/util"
+	"github.com/mgutz/ansi"
+)
+
+// defaultTopLogprobs is the number of alternatives requested for each token by --show-tokens, unless --top-logprobs
+// is set.
+const defaultTopLogprobs = 3
+
+var (
+	tokenHeaderColor       = ansi.ColorFunc("white+du")
+	highProbabilityColor   = ansi.ColorFunc("green")
+	mediumProbabilityColor = ansi.ColorFunc("yellow")
+	lowProbabilityColor    = ansi.ColorFunc("red")
+)
+
+// tokenProbability is a token from the response with its probability, as written by --show-tokens.
+type tokenProbability struct {
+	Token       string             `json:"token"`
+	Logprob     float64            `json:"logprob"`
+	Probability float64            `json:"probability"`
+	TopLogprobs []tokenProbability `json:"top_logprobs,omitempty"`
+}
+
+// tokensOutput is the JSON written by --show-tokens when the output is not a terminal.
+type tokensOutput struct {
+	Model        string             `json:"model,omitempty"`
+	Content      string             `json:"content"`
+	FinishReason string             `json:"finish_reason,omitempty"`
+	Tokens       []tokenProbability `json:"tokens"`
+}
+
+// requestLogprobs sets the parameters needed to show the probability of each token in the response.
+func requestLogprobs(mp *ModelParameters) error {
+	err := mp.SetParameterByName("logprobs", "true")
+	if err != nil {
+		return err
+	}
+	if _, ok := mp.values["top-logprobs"]; ok {
+		return nil
+	}
+	return mp.SetParameterByName("top-logprobs", strconv.Itoa(defaultTopLogprobs))
+}
+
+func newTokenProbability(logprob azuremodels.TokenLogprob) tokenProbability {
+	return tokenProbability{Token: logprob.Token, Logprob: logprob.Logprob, Probability: math.Exp(logprob.Logprob)}
+}
+
+// tokenProbabilities converts the log probabilities returned by the model to probabilities.
+func tokenProbabilities(logprobs []azuremodels.ChatTokenLogprob) []tokenProbability {
+	tokens := make([]tokenProbability, len(logprobs))
+	for i, logprob := range logprobs {
+		tokens[i] = newTokenProbability(logprob.TokenLogprob)
+		for _, alternative := range logprob.TopLogprobs {
+			tokens[i].TopLogprobs = append(tokens[i].TopLogprobs, newTokenProbability(alternative))
+		}
+	}
+	return tokens
+}
+
+// writeTokens writes each token of the response with its probability and the most likely alternatives: as a
+// colour-coded table in a terminal, and as JSON otherwise.
+func (h *runCommandHandler) writeTokens(result *completionResult) error {
+	tokens := tokenProbabilities(result.logprobs)
+
+	if !h.cfg.IsTerminalOutput {
+		data, err := json.MarshalIndent(tokensOutput{
+			Model:        result.model,
+			Content:      result.content,
+			FinishReason: result.finishReason,
+			Tokens:       tokens,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		h.writeToOut(string(data) + "\n")
+		return nil
+	}
+
+	if len(tokens) == 0 {
+		util.WriteToOut(h.cfg.ErrOut, "The model did not return the probabilities of its tokens.\n")
+		return nil
+	}
+
+	printer := h.cfg.NewTablePrinter()
+	printer.AddHeader([]string{"TOKEN", "PROBABILITY", "ALTERNATIVES"}, tableprinter.WithColor(tokenHeaderColor))
+	printer.EndRow()
+
+	for _, token := range tokens {
+		alternatives := []string{}
+		for _, alternative := range token.TopLogprobs {
+			if alternative.Token == token.Token {
+				continue
+			}
+			alternatives = append(alternatives, strconv.Quote(alternative.Token)+" "+formatProbability(alternative.Probability))
+		}
+
+		printer.AddField(strconv.Quote(token.Token))
+		printer.AddField(formatProbability(token.Probability), tableprinter.WithColor(probabilityColor(token.Probability)))
+		printer.AddField(strings.Join(alternatives, ", "))
+		printer.EndRow()
+	}
+
+	return printer.Render()
+}
+
+// probabilityColor returns the colour for a token with the given probability: green if the model was confident,
+// yellow if it was unsure, and red if the token was unlikely.
+func probabilityColor(probability float64) func(string) string {
+	switch {
+	case probability >= 0.9:
+		return highProbabilityColor
+	case probability >= 0.5:
+		return mediumProbabilityColor
+	default:
+		return lowProbabilityColor
+	}
+}
+
+func formatProbability(probability float64) string {
+	return fmt.Sprintf("%.2f%%", probability*100)
+}
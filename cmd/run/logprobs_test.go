@@ -0,0 +1,65 @@
+package run
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogprobs(t *testing.T) {
+	t.Run("tokenProbabilities converts log probabilities", func(t *testing.T) {
+		tokens := tokenProbabilities([]azuremodels.ChatTokenLogprob{{
+			TokenLogprob: azuremodels.TokenLogprob{Token: "Yes", Logprob: math.Log(0.75)},
+			TopLogprobs: []azuremodels.TokenLogprob{
+				{Token: "Yes", Logprob: math.Log(0.75)},
+				{Token: "No", Logprob: math.Log(0.25)},
+			},
+		}})
+
+		require.Len(t, tokens, 1)
+		require.InDelta(t, 0.75, tokens[0].Probability, 1e-9)
+		require.Len(t, tokens[0].TopLogprobs, 2)
+		require.Equal(t, "No", tokens[0].TopLogprobs[1].Token)
+		require.InDelta(t, 0.25, tokens[0].TopLogprobs[1].Probability, 1e-9)
+	})
+
+	t.Run("requestLogprobs keeps --top-logprobs", func(t *testing.T) {
+		mp := ModelParameters{}
+		require.NoError(t, mp.SetParameterByName("top-logprobs", "10"))
+
+		require.NoError(t, requestLogprobs(&mp))
+
+		require.Equal(t, "true", mp.FormatParameter("logprobs"))
+		require.Equal(t, "10", mp.FormatParameter("top-logprobs"))
+	})
+
+	t.Run("writeTokens writes a table with the alternatives in a terminal", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, nil, true, 80)
+		h := &runCommandHandler{cfg: cfg}
+
+		err := h.writeTokens(&completionResult{logprobs: []azuremodels.ChatTokenLogprob{{
+			TokenLogprob: azuremodels.TokenLogprob{Token: " Yes", Logprob: math.Log(0.6)},
+			TopLogprobs: []azuremodels.TokenLogprob{
+				{Token: " Yes", Logprob: math.Log(0.6)},
+				{Token: " No", Logprob: math.Log(0.3)},
+			},
+		}}})
+
+		require.NoError(t, err)
+		output := buf.String()
+		require.Contains(t, output, `" Yes"`)
+		require.Contains(t, output, mediumProbabilityColor("60.00%     "))
+		require.Contains(t, output, `" No" 30.00%`)
+	})
+
+	t.Run("probabilityColor", func(t *testing.T) {
+		require.Equal(t, highProbabilityColor("x"), probabilityColor(0.95)("x"))
+		require.Equal(t, mediumProbabilityColor("x"), probabilityColor(0.5)("x"))
+		require.Equal(t, lowProbabilityColor("x"), probabilityColor(0.1)("x"))
+	})
+}
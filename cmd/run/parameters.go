@@ -0,0 +1,267 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/settings"
+	"github.com/github/gh-models/pkg/util"
+	"github.com/spf13/pflag"
+)
+
+// modelParameter describes a parameter that can be set for a model run. Each parameter is available as a flag, and
+// with /set in interactive mode.
+type modelParameter struct {
+	name        string
+	description string
+	// parse parses and validates a value for the parameter.
+	parse func(value string) (any, error)
+	// apply sets the parsed value on the request.
+	apply func(req *azuremodels.ChatCompletionOptions, value any)
+}
+
+// newModelParameter returns a model parameter with a typed parse and apply function.
+func newModelParameter[T any](name, description string, parse func(string) (T, error), apply func(*azuremodels.ChatCompletionOptions, T)) modelParameter {
+	return modelParameter{
+		name:        name,
+		description: description,
+		parse: func(value string) (any, error) {
+			return parse(value)
+		},
+		apply: func(req *azuremodels.ChatCompletionOptions, value any) {
+			apply(req, value.(T))
+		},
+	}
+}
+
+// modelParameters lists the supported parameters, in the order they are displayed.
+var modelParameters = []modelParameter{
+	newModelParameter("max-tokens", "Limit the maximum tokens for the model response.",
+		parseIntInRange(1, 1<<31-1),
+		func(req *azuremodels.ChatCompletionOptions, value int) { req.MaxTokens = util.Ptr(value) }),
+	newModelParameter("temperature", "Controls randomness in the response, use lower to be more deterministic.",
+		parseFloatInRange(0, 2),
+		func(req *azuremodels.ChatCompletionOptions, value float64) { req.Temperature = util.Ptr(value) }),
+	newModelParameter("top-p", "Controls text diversity by selecting the most probable words until a set probability is reached.",
+		parseFloatInRange(0, 1),
+		func(req *azuremodels.ChatCompletionOptions, value float64) { req.TopP = util.Ptr(value) }),
+	newModelParameter("stop", "Sequences where the model stops generating further tokens, separated by commas (up to 4).",
+		parseStopSequences,
+		func(req *azuremodels.ChatCompletionOptions, value []string) { req.Stop = value }),
+	newModelParameter("seed", "Makes sampling deterministic, so repeated requests with the same seed return the same result.",
+		parseInt64,
+		func(req *azuremodels.ChatCompletionOptions, value int64) { req.Seed = util.Ptr(value) }),
+	newModelParameter("presence-penalty", "Penalizes tokens that have already appeared, to encourage new topics, from -2 to 2.",
+		parseFloatInRange(-2, 2),
+		func(req *azuremodels.ChatCompletionOptions, value float64) { req.PresencePenalty = util.Ptr(value) }),
+	newModelParameter("frequency-penalty", "Penalizes tokens by how often they have appeared, to reduce repetition, from -2 to 2.",
+		parseFloatInRange(-2, 2),
+		func(req *azuremodels.ChatCompletionOptions, value float64) { req.FrequencyPenalty = util.Ptr(value) }),
+	newModelParameter("n", "The number of responses to generate for each prompt.",
+		parseIntInRange(1, 128),
+		func(req *azuremodels.ChatCompletionOptions, value int) { req.N = util.Ptr(value) }),
+	newModelParameter("logit-bias", "Adjusts the likelihood of tokens, as comma-separated token-id:bias pairs with biases from -100 to 100.",
+		parseLogitBias,
+		func(req *azuremodels.ChatCompletionOptions, value map[string]int) { req.LogitBias = value }),
+	newModelParameter("logprobs", "Returns the log probability of each token in the response: true or false.",
+		strconv.ParseBool,
+		func(req *azuremodels.ChatCompletionOptions, value bool) { req.Logprobs = util.Ptr(value) }),
+	newModelParameter("top-logprobs", "The number of most likely alternatives to return for each token, from 0 to 20. Implies logprobs.",
+		parseIntInRange(0, 20),
+		func(req *azuremodels.ChatCompletionOptions, value int) {
+			req.TopLogprobs = util.Ptr(value)
+			if req.Logprobs == nil {
+				req.Logprobs = util.Ptr(true)
+			}
+		}),
+	newModelParameter("reasoning-effort", "Controls how much effort reasoning models spend thinking: low, medium or high.",
+		parseOneOf("low", "medium", "high"),
+		func(req *azuremodels.ChatCompletionOptions, value string) { req.ReasoningEffort = util.Ptr(value) }),
+}
+
+func findModelParameter(name string) (modelParameter, bool) {
+	for _, param := range modelParameters {
+		if param.name == name {
+			return param, true
+		}
+	}
+	return modelParameter{}, false
+}
+
+func modelParameterNames() []string {
+	names := make([]string, len(modelParameters))
+	for i, param := range modelParameters {
+		names[i] = param.name
+	}
+	return names
+}
+
+// ModelParameters represents the parameters that can be set for a model run.
+type ModelParameters struct {
+	values map[string]any
+	raw    map[string]string
+}
+
+// AddModelParameterFlags adds a flag for each supported model parameter to the given flags.
+func AddModelParameterFlags(flags *pflag.FlagSet) {
+	for _, param := range modelParameters {
+		flags.String(param.name, "", param.description)
+	}
+}
+
+// FormatParameter returns a string representation of the parameter value.
+func (mp *ModelParameters) FormatParameter(name string) string {
+	if value, ok := mp.raw[name]; ok {
+		return value
+	}
+	return "<not set>"
+}
+
+// PopulateFromSettings populates the model parameters from their environment variables, such as
+// GH_MODELS_TEMPERATURE, or else from the given values from the settings file.
+func (mp *ModelParameters) PopulateFromSettings(values map[string]string) error {
+	for _, param := range modelParameters {
+		envName := settings.EnvName(param.name)
+		if value := os.Getenv(envName); value != "" {
+			err := mp.SetParameterByName(param.name, value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %w", envName, err)
+			}
+			continue
+		}
+		if value, ok := values[param.name]; ok {
+			err := mp.SetParameterByName(param.name, value)
+			if err != nil {
+				return fmt.Errorf("invalid value for parameters.%s in the config file: %w", param.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// PopulateFromFlags populates the model parameters from the given flags.
+func (mp *ModelParameters) PopulateFromFlags(flags *pflag.FlagSet) error {
+	for _, param := range modelParameters {
+		value, err := flags.GetString(param.name)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			continue
+		}
+		err = mp.SetParameterByName(param.name, value)
+		if err != nil {
+			return fmt.Errorf("invalid value for --%s: %w", param.name, err)
+		}
+	}
+	return nil
+}
+
+// SetParameterByName sets the parameter with the given name to the given value.
+func (mp *ModelParameters) SetParameterByName(name, value string) error {
+	param, ok := findModelParameter(name)
+	if !ok {
+		return errors.New("unknown parameter '" + name + "'. Supported parameters: " + strings.Join(modelParameterNames(), ", "))
+	}
+
+	parsed, err := param.parse(value)
+	if err != nil {
+		return err
+	}
+
+	if mp.values == nil {
+		mp.values = make(map[string]any)
+		mp.raw = make(map[string]string)
+	}
+	mp.values[name] = parsed
+	mp.raw[name] = value
+
+	return nil
+}
+
+// UpdateRequest updates the given request with the model parameters.
+func (mp *ModelParameters) UpdateRequest(req *azuremodels.ChatCompletionOptions) {
+	for _, param := range modelParameters {
+		if value, ok := mp.values[param.name]; ok {
+			param.apply(req, value)
+		}
+	}
+}
+
+func parseIntInRange(minValue, maxValue int) func(string) (int, error) {
+	return func(value string) (int, error) {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not a whole number", value)
+		}
+		if parsed < minValue || parsed > maxValue {
+			return 0, fmt.Errorf("%d is out of range, it must be between %d and %d", parsed, minValue, maxValue)
+		}
+		return parsed, nil
+	}
+}
+
+// parseInt64 parses a 64-bit whole number, which doesn't fit in an int on 32-bit platforms.
+func parseInt64(value string) (int64, error) {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a whole number between %d and %d", value, int64(math.MinInt64), int64(math.MaxInt64))
+	}
+	return parsed, nil
+}
+
+func parseFloatInRange(minValue, maxValue float64) func(string) (float64, error) {
+	return func(value string) (float64, error) {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not a number", value)
+		}
+		if parsed < minValue || parsed > maxValue {
+			return 0, fmt.Errorf("%g is out of range, it must be between %g and %g", parsed, minValue, maxValue)
+		}
+		return parsed, nil
+	}
+}
+
+func parseOneOf(allowed ...string) func(string) (string, error) {
+	return func(value string) (string, error) {
+		for _, a := range allowed {
+			if value == a {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("'%s' is not supported, it must be one of: %s", value, strings.Join(allowed, ", "))
+	}
+}
+
+func parseStopSequences(value string) ([]string, error) {
+	sequences := strings.Split(value, ",")
+	if len(sequences) > 4 {
+		return nil, errors.New("at most 4 stop sequences are supported")
+	}
+	return sequences, nil
+}
+
+func parseLogitBias(value string) (map[string]int, error) {
+	bias := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		token, biasValue, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("'%s' must be in the form token-id:bias", pair)
+		}
+		if _, err := strconv.Atoi(token); err != nil {
+			return nil, fmt.Errorf("'%s' is not a token ID", token)
+		}
+		parsed, err := parseIntInRange(-100, 100)(biasValue)
+		if err != nil {
+			return nil, err
+		}
+		bias[token] = parsed
+	}
+	return bias, nil
+}
@@ -0,0 +1,109 @@
+package run
+
+import (
+	"math"
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelParameters(t *testing.T) {
+	t.Run("PopulateFromFlags and UpdateRequest", func(t *testing.T) {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		AddModelParameterFlags(flags)
+		err := flags.Parse([]string{
+			"--max-tokens", "100",
+			"--temperature", "0.5",
+			"--top-p", "0.9",
+			"--stop", "END,STOP",
+			"--seed", "42",
+			"--presence-penalty", "-1.5",
+			"--frequency-penalty", "1",
+			"--n", "3",
+			"--logit-bias", "50256:-100, 1234:5",
+			"--logprobs", "true",
+			"--reasoning-effort", "low",
+		})
+		require.NoError(t, err)
+		mp := ModelParameters{}
+
+		err = mp.PopulateFromFlags(flags)
+		require.NoError(t, err)
+
+		req := azuremodels.ChatCompletionOptions{}
+		mp.UpdateRequest(&req)
+		require.Equal(t, 100, *req.MaxTokens)
+		require.Equal(t, 0.5, *req.Temperature)
+		require.Equal(t, 0.9, *req.TopP)
+		require.Equal(t, []string{"END", "STOP"}, req.Stop)
+		require.Equal(t, int64(42), *req.Seed)
+		require.Equal(t, -1.5, *req.PresencePenalty)
+		require.Equal(t, 1.0, *req.FrequencyPenalty)
+		require.Equal(t, 3, *req.N)
+		require.Equal(t, map[string]int{"50256": -100, "1234": 5}, req.LogitBias)
+		require.True(t, *req.Logprobs)
+		require.Equal(t, "low", *req.ReasoningEffort)
+	})
+
+	t.Run("seed accepts any 64-bit number", func(t *testing.T) {
+		mp := ModelParameters{}
+		require.NoError(t, mp.SetParameterByName("seed", "9223372036854775807"))
+		req := azuremodels.ChatCompletionOptions{}
+
+		mp.UpdateRequest(&req)
+
+		require.Equal(t, int64(math.MaxInt64), *req.Seed)
+		require.EqualError(t, mp.SetParameterByName("seed", "9223372036854775808"), "'9223372036854775808' is not a whole number between -9223372036854775808 and 9223372036854775807")
+	})
+
+	t.Run("top-logprobs requests logprobs", func(t *testing.T) {
+		mp := ModelParameters{}
+		require.NoError(t, mp.SetParameterByName("top-logprobs", "5"))
+		req := azuremodels.ChatCompletionOptions{}
+
+		mp.UpdateRequest(&req)
+
+		require.Equal(t, 5, *req.TopLogprobs)
+		require.True(t, *req.Logprobs)
+		require.EqualError(t, mp.SetParameterByName("top-logprobs", "21"), "21 is out of range, it must be between 0 and 20")
+	})
+
+	t.Run("unset parameters are not sent", func(t *testing.T) {
+		mp := ModelParameters{}
+		req := azuremodels.ChatCompletionOptions{}
+
+		mp.UpdateRequest(&req)
+
+		require.Equal(t, azuremodels.ChatCompletionOptions{}, req)
+		require.Equal(t, "<not set>", mp.FormatParameter("temperature"))
+	})
+
+	t.Run("SetParameterByName", func(t *testing.T) {
+		mp := ModelParameters{}
+
+		require.NoError(t, mp.SetParameterByName("temperature", "1.2"))
+		require.Equal(t, "1.2", mp.FormatParameter("temperature"))
+
+		require.EqualError(t, mp.SetParameterByName("temperature", "3"), "3 is out of range, it must be between 0 and 2")
+		require.EqualError(t, mp.SetParameterByName("n", "many"), "'many' is not a whole number")
+		require.EqualError(t, mp.SetParameterByName("stop", "a,b,c,d,e"), "at most 4 stop sequences are supported")
+		require.EqualError(t, mp.SetParameterByName("logit-bias", "50256"), "'50256' must be in the form token-id:bias")
+		require.EqualError(t, mp.SetParameterByName("logit-bias", "50256:101"), "101 is out of range, it must be between -100 and 100")
+		require.EqualError(t, mp.SetParameterByName("reasoning-effort", "max"), "'max' is not supported, it must be one of: low, medium, high")
+		require.ErrorContains(t, mp.SetParameterByName("volume", "11"), "unknown parameter 'volume'. Supported parameters: max-tokens, temperature, top-p, stop")
+		require.Equal(t, "1.2", mp.FormatParameter("temperature"), "invalid values don't replace valid ones")
+	})
+
+	t.Run("PopulateFromFlags names the invalid flag", func(t *testing.T) {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		AddModelParameterFlags(flags)
+		require.NoError(t, flags.Parse([]string{"--top-p", "2"}))
+		mp := ModelParameters{}
+
+		err := mp.PopulateFromFlags(flags)
+
+		require.EqualError(t, err, "invalid value for --top-p: 2 is out of range, it must be between 0 and 1")
+	})
+}
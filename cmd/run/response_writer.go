@@ -0,0 +1,204 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cli/cli/v2/pkg/markdown"
+	"github.com/cli/go-gh/v2/pkg/text"
+	"github.com/github/gh-models/pkg/command"
+)
+
+// responseWriter writes the model's response to the output as it is streamed.
+type responseWriter interface {
+	// write writes the next chunk of the response.
+	write(content string)
+	// flush writes anything that is still buffered, at the end of the response.
+	flush() error
+}
+
+// responseOptions controls how the model's response is written.
+type responseOptions struct {
+	// renderMarkdown renders the response as markdown when writing to a terminal.
+	renderMarkdown bool
+	// codeOnly writes only the code blocks from the response.
+	codeOnly bool
+	// codeBlocks selects which code blocks to write when codeOnly is set.
+	codeBlocks codeBlockSelector
+	// thinking controls how the thinking sections of reasoning models are displayed.
+	thinking thinkingMode
+	// showTokens writes the probability of each token after the response, or as JSON instead of the response when not
+	// writing to a terminal.
+	showTokens bool
+}
+
+// newResponseWriter returns the writer to use for the response, based on the given configuration.
+func newResponseWriter(cfg *command.Config, opts responseOptions) responseWriter {
+	var out responseWriter
+	switch {
+	case opts.showTokens && !cfg.IsTerminalOutput:
+		// The response is written as part of the JSON instead.
+		out = discardResponseWriter{}
+	case opts.codeOnly:
+		out = &codeResponseWriter{cfg: cfg, selector: opts.codeBlocks}
+	case opts.renderMarkdown && cfg.IsTerminalOutput:
+		out = &markdownResponseWriter{cfg: cfg}
+	default:
+		out = &rawResponseWriter{cfg: cfg}
+	}
+	return newThinkingResponseWriter(cfg, out, opts.thinking)
+}
+
+// rawResponseWriter writes the response exactly as the model returns it.
+type rawResponseWriter struct {
+	cfg *command.Config
+}
+
+func (w *rawResponseWriter) write(content string) {
+	w.cfg.WriteToOut(content)
+}
+
+func (w *rawResponseWriter) flush() error {
+	return nil
+}
+
+// discardResponseWriter discards the response, for when it is written some other way.
+type discardResponseWriter struct{}
+
+func (discardResponseWriter) write(string) {}
+
+func (discardResponseWriter) flush() error {
+	return nil
+}
+
+// markdownResponseWriter renders the response as markdown. Text is written as-is while it streams in, and each block is
+// re-rendered in place once it is complete.
+type markdownResponseWriter struct {
+	cfg *command.Config
+	// pending is the text of the current, incomplete block, which has been written to the terminal unrendered.
+	pending string
+}
+
+func (w *markdownResponseWriter) write(content string) {
+	w.cfg.WriteToOut(content)
+	w.pending += content
+
+	for {
+		block, rest, ok := splitMarkdownBlock(w.pending)
+		if !ok {
+			return
+		}
+		w.replacePending(block, rest)
+	}
+}
+
+func (w *markdownResponseWriter) flush() error {
+	if strings.TrimSpace(w.pending) != "" {
+		w.replacePending(w.pending, "")
+	}
+	return nil
+}
+
+// replacePending erases the unrendered text from the terminal, renders block in its place and writes rest unrendered.
+func (w *markdownResponseWriter) replacePending(block, rest string) {
+	if up := w.terminalRows(w.pending) - 1; up > 0 {
+		w.cfg.WriteToOut(fmt.Sprintf("\x1b[%dA", up))
+	}
+	// Move to the start of the line and clear everything after the cursor.
+	w.cfg.WriteToOut("\r\x1b[J")
+
+	rendered, err := markdown.Render(block, markdown.WithTheme(w.cfg.Theme()), markdown.WithWrap(w.cfg.TerminalWidth))
+	if err != nil {
+		rendered = block
+	}
+	rendered = strings.Trim(rendered, "\n") + "\n"
+	if rest != "" {
+		// Keep blocks visually separated, as they were in the original markdown.
+		rendered += "\n"
+	}
+
+	w.cfg.WriteToOut(rendered + rest)
+	w.pending = rest
+}
+
+// terminalRows returns the number of rows the given text takes up in the terminal, once long lines are wrapped.
+func (w *markdownResponseWriter) terminalRows(s string) int {
+	rows := 0
+	for _, line := range strings.Split(s, "\n") {
+		width := text.DisplayWidth(line)
+		if w.cfg.TerminalWidth <= 0 || width <= w.cfg.TerminalWidth {
+			rows++
+			continue
+		}
+		rows += (width + w.cfg.TerminalWidth - 1) / w.cfg.TerminalWidth
+	}
+	return rows
+}
+
+// splitMarkdownBlock splits off the first complete block of the given markdown: everything up to a blank line or the end
+// of a fenced code block. It returns false if the markdown does not contain a complete block yet.
+func splitMarkdownBlock(s string) (string, string, bool) {
+	inFence := false
+	fence := ""
+	hasContent := false
+	offset := 0
+
+	for {
+		newline := strings.IndexByte(s[offset:], '\n')
+		if newline < 0 {
+			return "", "", false
+		}
+		line := strings.TrimSpace(s[offset : offset+newline])
+		end := offset + newline + 1
+
+		switch {
+		case inFence:
+			// Only a fence at least as long as the opening one closes the block.
+			if strings.HasPrefix(line, fence) && strings.Trim(line, fence[:1]) == "" {
+				return s[:end], s[end:], true
+			}
+
+		case fenceMarker(line) != "":
+			inFence = true
+			fence = fenceMarker(line)
+			hasContent = true
+
+		case line == "":
+			if hasContent {
+				return s[:end], s[end:], true
+			}
+
+		default:
+			hasContent = true
+		}
+
+		offset = end
+	}
+}
+
+// codeResponseWriter buffers the response, and writes only the selected code blocks from it once it is complete.
+type codeResponseWriter struct {
+	cfg      *command.Config
+	selector codeBlockSelector
+	content  strings.Builder
+}
+
+func (w *codeResponseWriter) write(content string) {
+	w.content.WriteString(content)
+}
+
+func (w *codeResponseWriter) flush() error {
+	blocks, err := w.selector.selectBlocks(extractCodeBlocks(w.content.String()))
+	w.content.Reset()
+	if err != nil {
+		return err
+	}
+
+	for i, block := range blocks {
+		if i > 0 {
+			w.cfg.WriteToOut("\n")
+		}
+		w.cfg.WriteToOut(block.code)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package run
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/github/gh-models/pkg/command"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseWriter(t *testing.T) {
+	t.Run("writes raw output when not writing to a terminal", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, nil, false, 80)
+		out := newResponseWriter(cfg, responseOptions{renderMarkdown: true})
+
+		out.write("# Title\n\nSome *text*")
+		require.NoError(t, out.flush())
+
+		require.IsType(t, &rawResponseWriter{}, out)
+		require.Equal(t, "# Title\n\nSome *text*", buf.String())
+	})
+
+	t.Run("re-renders each complete block in place", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, nil, true, 80)
+		out := newResponseWriter(cfg, responseOptions{renderMarkdown: true})
+
+		out.write("# Ti")
+		require.Equal(t, "# Ti", buf.String())
+
+		out.write("tle\n\nSome")
+		output := buf.String()
+		// The raw block spans three rows, so the cursor moves up two rows before rendering.
+		require.Contains(t, output, "\x1b[2A\r\x1b[J")
+		require.Contains(t, output, "Title")
+		require.True(t, bytes.HasSuffix(buf.Bytes(), []byte("\n\nSome")))
+
+		require.NoError(t, out.flush())
+		require.Contains(t, buf.String()[len(output):], "\r\x1b[J")
+	})
+
+	t.Run("writes only the selected code blocks", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, nil, true, 80)
+		out := newResponseWriter(cfg, responseOptions{codeOnly: true, codeBlocks: codeBlockSelector{language: "sh"}})
+
+		out.write("Here you go:\n\n```sh\necho hi\n```\n\n```python\nprint('hi')\n```\n")
+		require.Empty(t, buf.String())
+
+		require.NoError(t, out.flush())
+		require.Equal(t, "echo hi\n", buf.String())
+	})
+
+	t.Run("returns an error when there is no code to write", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, nil, false, 80)
+		out := newResponseWriter(cfg, responseOptions{codeOnly: true})
+
+		out.write("I can't help with that.")
+
+		require.EqualError(t, out.flush(), "the response does not contain any code blocks")
+		require.Empty(t, buf.String())
+	})
+
+	t.Run("splitMarkdownBlock", func(t *testing.T) {
+		_, _, ok := splitMarkdownBlock("a paragraph that is still streaming")
+		require.False(t, ok)
+
+		block, rest, ok := splitMarkdownBlock("first\nparagraph\n\nsecond")
+		require.True(t, ok)
+		require.Equal(t, "first\nparagraph\n\n", block)
+		require.Equal(t, "second", rest)
+
+		_, _, ok = splitMarkdownBlock("```go\nfunc main() {\n\n")
+		require.False(t, ok, "blank lines inside a code fence do not end the block")
+
+		block, rest, ok = splitMarkdownBlock("```go\nfunc main() {\n\n}\n```\nafter")
+		require.True(t, ok)
+		require.Equal(t, "```go\nfunc main() {\n\n}\n```\n", block)
+		require.Equal(t, "after", rest)
+
+		_, _, ok = splitMarkdownBlock("````markdown\n```go\n\nfmt.Println()\n```\n\n")
+		require.False(t, ok, "a shorter fence does not close a longer one")
+
+		block, rest, ok = splitMarkdownBlock("````markdown\n```go\n\n```\n`````\nafter")
+		require.True(t, ok)
+		require.Equal(t, "````markdown\n```go\n\n```\n`````\n", block)
+		require.Equal(t, "after", rest)
+	})
+}
@@ -8,129 +8,26 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/briandowns/spinner"
+	"github.com/cli/go-gh/v2/pkg/text"
 	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/catalog"
+	"github.com/github/gh-models/internal/settings"
 	"github.com/github/gh-models/internal/sse"
 	"github.com/github/gh-models/pkg/command"
 	"github.com/github/gh-models/pkg/util"
 	"github.com/MakeNowJust/heredoc"
+	"github.com/mgutz/ansi"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
-// ModelParameters represents the parameters that can be set for a model run.
-type ModelParameters struct {
-	maxTokens   *int
-	temperature *float64
-	topP        *float64
-}
-
-// FormatParameter returns a string representation of the parameter value.
-func (mp *ModelParameters) FormatParameter(name string) string {
-	switch name {
-	case "max-tokens":
-		if mp.maxTokens != nil {
-			return strconv.Itoa(*mp.maxTokens)
-		}
-
-	case "temperature":
-		if mp.temperature != nil {
-			return fmt.Sprintf("%f", *mp.temperature)
-		}
-
-	case "top-p":
-		if mp.topP != nil {
-			return fmt.Sprintf("%f", *mp.topP)
-		}
-	}
-
-	return "<not set>"
-}
-
-// PopulateFromFlags populates the model parameters from the given flags.
-func (mp *ModelParameters) PopulateFromFlags(flags *pflag.FlagSet) error {
-	maxTokensString, err := flags.GetString("max-tokens")
-	if err != nil {
-		return err
-	}
-	if maxTokensString != "" {
-		maxTokens, err := strconv.Atoi(maxTokensString)
-		if err != nil {
-			return err
-		}
-		mp.maxTokens = util.Ptr(maxTokens)
-	}
-
-	temperatureString, err := flags.GetString("temperature")
-	if err != nil {
-		return err
-	}
-	if temperatureString != "" {
-		temperature, err := strconv.ParseFloat(temperatureString, 64)
-		if err != nil {
-			return err
-		}
-		mp.temperature = util.Ptr(temperature)
-	}
-
-	topPString, err := flags.GetString("top-p")
-	if err != nil {
-		return err
-	}
-	if topPString != "" {
-		topP, err := strconv.ParseFloat(topPString, 64)
-		if err != nil {
-			return err
-		}
-		mp.topP = util.Ptr(topP)
-	}
-
-	return nil
-}
-
-// SetParameterByName sets the parameter with the given name to the given value.
-func (mp *ModelParameters) SetParameterByName(name, value string) error {
-	switch name {
-	case "max-tokens":
-		maxTokens, err := strconv.Atoi(value)
-		if err != nil {
-			return err
-		}
-		mp.maxTokens = util.Ptr(maxTokens)
-
-	case "temperature":
-		temperature, err := strconv.ParseFloat(value, 64)
-		if err != nil {
-			return err
-		}
-		mp.temperature = util.Ptr(temperature)
-
-	case "top-p":
-		topP, err := strconv.ParseFloat(value, 64)
-		if err != nil {
-			return err
-		}
-		mp.topP = util.Ptr(topP)
-
-	default:
-		return errors.New("unknown parameter '" + name + "'. Supported parameters: max-tokens, temperature, top-p")
-	}
-
-	return nil
-}
-
-// UpdateRequest updates the given request with the model parameters.
-func (mp *ModelParameters) UpdateRequest(req *azuremodels.ChatCompletionOptions) {
-	req.MaxTokens = mp.maxTokens
-	req.Temperature = mp.temperature
-	req.TopP = mp.topP
-}
-
 // Conversation represents a conversation between the user and the model.
 type Conversation struct {
 	messages     []azuremodels.ChatMessage
@@ -175,6 +72,44 @@ func (c *Conversation) Reset() {
 	c.messages = nil
 }
 
+// lastAssistantMessage returns the content of the model's most recent response, if any.
+func (c *Conversation) lastAssistantMessage() string {
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		message := c.messages[i]
+		if message.Role == azuremodels.ChatMessageRoleAssistant && message.Content != nil {
+			return *message.Content
+		}
+	}
+	return ""
+}
+
+// appendToLastMessage appends the given content to the most recent message in the conversation.
+func (c *Conversation) appendToLastMessage(content string) {
+	if len(c.messages) == 0 {
+		return
+	}
+	last := &c.messages[len(c.messages)-1]
+	existing := ""
+	if last.Content != nil {
+		existing = *last.Content
+	}
+	last.Content = util.Ptr(existing + content)
+}
+
+// dropOldestTurn removes the oldest message, along with the model's reply to it, and returns the number of messages
+// removed.
+func (c *Conversation) dropOldestTurn() int {
+	if len(c.messages) == 0 {
+		return 0
+	}
+	count := 1
+	if len(c.messages) > 2 && c.messages[1].Role == azuremodels.ChatMessageRoleAssistant {
+		count = 2
+	}
+	c.messages = c.messages[count:]
+	return count
+}
+
 func isPipe(r io.Reader) bool {
 	if f, ok := r.(*os.File); ok {
 		stat, err := f.Stat()
@@ -188,6 +123,10 @@ func isPipe(r io.Reader) bool {
 	return false
 }
 
+var (
+	candidateHeaderColor = ansi.ColorFunc("white+b")
+)
+
 // NewRunCommand returns a new gh command for running a model.
 func NewRunCommand(cfg *command.Config) *cobra.Command {
 	cmd := &cobra.Command{
@@ -203,6 +142,21 @@ func NewRunCommand(cfg *command.Config) *cobra.Command {
 			If you know which model you want to run inference with, you can run the request in a single command
 			as %[1]sgh models run [model] [prompt]%[1]s
 
+			To make sure a model doesn't change underneath you, pin its version as %[1]s[model]@[version]%[1]s.
+			Only the latest version of a model runs, so the run fails once a newer version replaces the pinned
+			one. Run %[1]sgh models list --all-versions%[1]s to see the versions of each model.
+
+			With %[1]s--fallback m1,m2%[1]s, or the %[1]sfallback%[1]s setting, a request that is rate limited or fails
+			with a server error is retried on the next model, before anything is written. The retries are
+			reported on standard error, and the JSON written by %[1]s--show-tokens%[1]s names the model that answered.
+
+			With %[1]s--auto%[1]s, the model is selected from the catalog, and every argument is part of the prompt.
+			%[1]s--needs%[1]s and %[1]s--min-context%[1]s restrict the selection to models with those capabilities and
+			context size. The models in the %[1]sprefer%[1]s setting are selected first, and then models in the
+			high rate limit tier, the most popular models, and those with the largest context. The selected
+			model, and why it was selected, are reported on standard error, so scripts keep working when models
+			are retired.
+
 			The return value will be the response to your prompt from the selected model.
 		`, "`"),
 		Example: "gh models run gpt-4o-mini \"how many types of hyena are there?\"",
@@ -213,12 +167,36 @@ func NewRunCommand(cfg *command.Config) *cobra.Command {
 				return nil
 			}
 
-			models, err := cmdHandler.loadModels()
+			profileName, err := cmd.Flags().GetString("profile")
+			if err != nil {
+				return err
+			}
+			profile, err := cfg.Settings.Resolve(profileName)
+			if err != nil {
+				return err
+			}
+
+			sortOrder, err := cmd.Flags().GetString("sort")
 			if err != nil {
 				return err
 			}
 
-			modelName, err := cmdHandler.getModelNameFromArgs(models)
+			models, err := cmdHandler.loadModels(sortOrder)
+			if err != nil {
+				return err
+			}
+
+			cmdHandler.requirements, err = parseRequirements(cmd.Flags(), cfg.Settings.PreferredModels())
+			if err != nil {
+				return err
+			}
+
+			modelName, promptArgs, err := cmdHandler.getModelNameFromArgs(models, profile)
+			if err != nil {
+				return err
+			}
+
+			err = cmdHandler.setFallback(cmd.Flags(), models, profile)
 			if err != nil {
 				return err
 			}
@@ -226,8 +204,8 @@ func NewRunCommand(cfg *command.Config) *cobra.Command {
 			initialPrompt := ""
 			singleShot := false
 
-			if len(args) > 1 {
-				initialPrompt = strings.Join(args[1:], " ")
+			if len(promptArgs) > 0 {
+				initialPrompt = strings.Join(promptArgs, " ")
 				singleShot = true
 			}
 
@@ -243,17 +221,55 @@ func NewRunCommand(cfg *command.Config) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if !cmd.Flags().Changed("system-prompt") {
+				systemPrompt = profile.SystemPrompt
+			}
 
 			conversation := Conversation{
 				systemPrompt: systemPrompt,
 			}
 
+			strategyName, err := cmd.Flags().GetString("context-strategy")
+			if err != nil {
+				return err
+			}
+			strategy, err := parseContextStrategy(strategyName)
+			if err != nil {
+				return err
+			}
+
+			window := contextWindow{
+				maxInputTokens: cmdHandler.getContextLimit(modelName, models),
+				strategy:       strategy,
+			}
+
 			mp := ModelParameters{}
+			err = mp.PopulateFromSettings(profile.Parameters)
+			if err != nil {
+				return err
+			}
 			err = mp.PopulateFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
 
+			responseOpts, err := parseResponseOptions(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			if responseOpts.showTokens {
+				err = requestLogprobs(&mp)
+				if err != nil {
+					return err
+				}
+			}
+
+			autoContinue, err := cmd.Flags().GetInt("auto-continue")
+			if err != nil {
+				return err
+			}
+
 			for {
 				prompt := ""
 				if initialPrompt != "" {
@@ -281,18 +297,36 @@ func NewRunCommand(cfg *command.Config) *cobra.Command {
 						break
 					}
 
+					if prompt == "/continue" {
+						err = cmdHandler.handleContinuePrompt(&conversation, &window, modelName, mp, responseOpts)
+						if err != nil {
+							return err
+						}
+						continue
+					}
+
 					if prompt == "/parameters" {
-						cmdHandler.handleParametersPrompt(conversation, mp)
+						cmdHandler.handleParametersPrompt(conversation, &mp)
 						continue
 					}
 
 					if prompt == "/reset" || prompt == "/clear" {
-						cmdHandler.handleResetPrompt(conversation)
+						cmdHandler.handleResetPrompt(&conversation)
+						continue
+					}
+
+					if prompt == "/tokens" {
+						cmdHandler.handleTokensPrompt(&conversation, &window)
 						continue
 					}
 
 					if strings.HasPrefix(prompt, "/set ") {
-						cmdHandler.handleSetPrompt(prompt, mp)
+						cmdHandler.handleSetPrompt(prompt, &mp)
+						continue
+					}
+
+					if strings.HasPrefix(prompt, "/save-code ") {
+						cmdHandler.handleSaveCodePrompt(prompt, &conversation)
 						continue
 					}
 
@@ -312,6 +346,11 @@ func NewRunCommand(cfg *command.Config) *cobra.Command {
 
 				conversation.AddMessage(azuremodels.ChatMessageRoleUser, prompt)
 
+				err = cmdHandler.fitContextWindow(&conversation, &window, modelName, mp)
+				if err != nil {
+					return err
+				}
+
 				req := azuremodels.ChatCompletionOptions{
 					Messages: conversation.GetMessages(),
 					Model:    modelName,
@@ -319,47 +358,41 @@ func NewRunCommand(cfg *command.Config) *cobra.Command {
 
 				mp.UpdateRequest(&req)
 
-				sp := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(cmdHandler.cfg.ErrOut))
-				sp.Start()
-				//nolint:gocritic,revive // TODO
-				defer sp.Stop()
+				out := newResponseWriter(cmdHandler.cfg, responseOpts)
 
-				reader, err := cmdHandler.getChatCompletionStreamReader(req)
+				results, err := cmdHandler.streamCompletion(req, out)
 				if err != nil {
 					return err
 				}
-				//nolint:gocritic,revive // TODO
-				defer reader.Close()
 
-				messageBuilder := strings.Builder{}
-
-				for {
-					completion, err := reader.Read()
+				if len(results) > 1 {
+					// Candidates are written as a whole, so they aren't continued automatically. Use /continue instead.
+					result, err := cmdHandler.chooseCandidate(results, responseOpts, !singleShot)
 					if err != nil {
-						if errors.Is(err, io.EOF) {
-							break
-						}
 						return err
 					}
+					conversation.AddMessage(azuremodels.ChatMessageRoleAssistant, stripThinking(result.content))
+				} else {
+					result := results[0]
+					conversation.AddMessage(azuremodels.ChatMessageRoleAssistant, stripThinking(result.content))
 
-					sp.Stop()
-
-					for _, choice := range completion.Choices {
-						err = cmdHandler.handleCompletionChoice(choice, messageBuilder)
+					for i := 0; i < autoContinue && result.finishReason == azuremodels.FinishReasonLength; i++ {
+						next, err := cmdHandler.continueCompletion(&conversation, &window, modelName, mp, out)
 						if err != nil {
 							return err
 						}
+						result.extend(next)
 					}
-				}
 
-				cmdHandler.writeToOut("\n")
-				_, err = messageBuilder.WriteString("\n")
-				if err != nil {
-					return err
+					err = cmdHandler.finishResponse(out, result, responseOpts)
+					if err != nil {
+						if singleShot {
+							return err
+						}
+						util.WriteToOut(cmdHandler.cfg.ErrOut, err.Error()+"\n")
+					}
 				}
 
-				conversation.AddMessage(azuremodels.ChatMessageRoleAssistant, messageBuilder.String())
-
 				if singleShot {
 					break
 				}
@@ -369,101 +402,251 @@ func NewRunCommand(cfg *command.Config) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().String("max-tokens", "", "Limit the maximum tokens for the model response.")
-	cmd.Flags().String("temperature", "", "Controls randomness in the response, use lower to be more deterministic.")
-	cmd.Flags().String("top-p", "", "Controls text diversity by selecting the most probable words until a set probability is reached.")
+	AddModelParameterFlags(cmd.Flags())
 	cmd.Flags().String("system-prompt", "", "Prompt the system.")
+	cmd.Flags().String("profile", "", "Use the settings from this profile in the config file.")
+	cmd.Flags().String("sort", "", "Sort the models to select from by name, popularity, publisher or context.")
+	cmd.Flags().StringSlice("fallback", nil, "Models to retry the request on, in order, when the model is rate limited or unavailable.")
+	cmd.Flags().Bool("auto", false, "Select the best available model, instead of taking the model as the first argument.")
+	cmd.Flags().StringSlice("needs", nil, "Capabilities the selected model needs: vision, audio, json, tools, reasoning or language:<language>. Implies --auto.")
+	cmd.Flags().Int("min-context", 0, "The number of input tokens the selected model needs. Implies --auto.")
+	cmd.Flags().Bool("markdown", false, "Render responses as markdown when writing to a terminal.")
+	cmd.Flags().Bool("code-only", false, "Print only the code blocks from the response.")
+	cmd.Flags().Int("code-block", 0, "Print only the code block with this 1-based index. Implies --code-only.")
+	cmd.Flags().String("code-language", "", "Print only code blocks in this language. Implies --code-only.")
+	cmd.Flags().String("thinking", string(thinkingModeCollapse), "How to display the thinking of reasoning models: show, collapse or hide.")
+	cmd.Flags().Bool("show-tokens", false, "Show the probability of each token in the response and its most likely alternatives.")
+	cmd.Flags().Int("auto-continue", 0, "Automatically continue responses that are cut off by the token limit, up to this many times.")
+	cmd.Flags().String("context-strategy", string(contextStrategyPinSystem), "What to do when the conversation exceeds the model's context window: drop-oldest, pin-system, summarize or none.")
 
 	return cmd
 }
 
+func parseResponseOptions(flags *pflag.FlagSet) (responseOptions, error) {
+	opts := responseOptions{}
+	var err error
+
+	opts.renderMarkdown, err = flags.GetBool("markdown")
+	if err != nil {
+		return opts, err
+	}
+
+	opts.codeOnly, err = flags.GetBool("code-only")
+	if err != nil {
+		return opts, err
+	}
+
+	opts.codeBlocks.index, err = flags.GetInt("code-block")
+	if err != nil {
+		return opts, err
+	}
+	if opts.codeBlocks.index < 0 {
+		return opts, errors.New("--code-block must be a positive number")
+	}
+
+	opts.codeBlocks.language, err = flags.GetString("code-language")
+	if err != nil {
+		return opts, err
+	}
+
+	if opts.codeBlocks.index > 0 || opts.codeBlocks.language != "" {
+		opts.codeOnly = true
+	}
+
+	thinking, err := flags.GetString("thinking")
+	if err != nil {
+		return opts, err
+	}
+	opts.thinking, err = parseThinkingMode(thinking)
+	if err != nil {
+		return opts, err
+	}
+
+	opts.showTokens, err = flags.GetBool("show-tokens")
+	if err != nil {
+		return opts, err
+	}
+
+	return opts, nil
+}
+
 type runCommandHandler struct {
 	ctx    context.Context
 	cfg    *command.Config
 	client azuremodels.Client
 	args   []string
+	// requirements describes the model to select automatically with --auto, or is nil.
+	requirements *catalog.Requirements
+	// fallback lists the models that requests fall back to, in order, if any.
+	fallback []string
 }
 
 func newRunCommandHandler(cmd *cobra.Command, cfg *command.Config, args []string) *runCommandHandler {
 	return &runCommandHandler{ctx: cmd.Context(), cfg: cfg, client: cfg.Client, args: args}
 }
 
-func (h *runCommandHandler) loadModels() ([]*azuremodels.ModelSummary, error) {
+// loadModels returns the available models, in the order they are offered when prompting for a model.
+func (h *runCommandHandler) loadModels(sortOrder string) ([]*azuremodels.ModelSummary, error) {
 	models, err := h.client.ListModels(h.ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	azuremodels.SortModels(models)
+	if err := h.cfg.SortModels(h.ctx, models, sortOrder); err != nil {
+		return nil, err
+	}
 	return models, nil
 }
 
-func (h *runCommandHandler) getModelNameFromArgs(models []*azuremodels.ModelSummary) (string, error) {
+// getModelNameFromArgs returns the model to run and the arguments that make up the prompt. If the profile has a
+// default model, the model argument can be left out. With --auto, every argument is part of the prompt.
+func (h *runCommandHandler) getModelNameFromArgs(models []*azuremodels.ModelSummary, profile settings.Profile) (string, []string, error) {
+	defaultModel := profile.Model
 	modelName := ""
+	var promptArgs []string
 
 	switch {
-	case len(h.args) == 0:
-		// Need to prompt for a model
-		prompt := &survey.Select{
-			Message: "Select a model:",
-			Options: []string{},
+	case h.requirements != nil:
+		selected, err := h.selectModel(models, *h.requirements)
+		if err != nil {
+			return "", nil, err
 		}
+		modelName = selected
+		promptArgs = h.args
 
-		for _, model := range models {
-			if !model.IsChatModel() {
-				continue
-			}
-			prompt.Options = append(prompt.Options, model.FriendlyName)
-		}
+	case len(h.args) == 0 && defaultModel != "":
+		modelName = defaultModel
 
-		err := survey.AskOne(prompt, &modelName, survey.WithPageSize(10))
+	case len(h.args) == 0:
+		// Need to prompt for a model
+		picked, err := h.cfg.PickModel(h.ctx, models)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
+		modelName = picked.Name
 
-	case len(h.args) >= 1:
-		modelName = h.args[0]
+	case defaultModel != "" && !isModelName(h.cfg.Settings.ResolveModel(h.args[0], profile), models):
+		modelName = defaultModel
+		promptArgs = h.args
+
+	default:
+		modelName = h.cfg.Settings.ResolveModel(h.args[0], profile)
+		promptArgs = h.args[1:]
 	}
 
-	return validateModelName(modelName, models)
+	if err := azuremodels.CheckProvider(h.cfg.Client, modelName); err != nil {
+		return "", nil, err
+	}
+	model, err := validateModelName(modelName, models)
+	if err != nil {
+		return "", nil, err
+	}
+	if model.IsDeprecated() {
+		util.WriteToOut(h.cfg.ErrOut, fmt.Sprintf("Warning: %s is deprecated, and will be removed.\n", model.Name))
+	}
+	h.cfg.RememberModel(model.Name)
+	return model.Name, promptArgs, nil
 }
 
-func validateModelName(modelName string, models []*azuremodels.ModelSummary) (string, error) {
-	noMatchErrorMessage := "The specified model name is not found. Run 'gh models list' to see available models or 'gh models run' to select interactively."
+func isModelName(name string, models []*azuremodels.ModelSummary) bool {
+	name, _ = azuremodels.SplitModelVersion(name)
+	for _, model := range models {
+		if model.HasName(name) {
+			return true
+		}
+	}
+	return false
+}
 
-	if modelName == "" {
-		return "", errors.New(noMatchErrorMessage)
+// getContextLimit returns the input token limit to fit the conversation to: the smallest known limit of the model and
+// its fallback models, since any of them may answer a request, or 0 if none is known.
+func (h *runCommandHandler) getContextLimit(modelName string, models []*azuremodels.ModelSummary) int {
+	limit := h.getMaxInputTokens(modelName, models)
+	for _, name := range h.fallback {
+		if fallbackLimit := h.getMaxInputTokens(name, models); fallbackLimit > 0 && (limit == 0 || fallbackLimit < limit) {
+			limit = fallbackLimit
+		}
 	}
+	return limit
+}
 
-	foundMatch := false
+// getMaxInputTokens returns the input token limit of the given model, or 0 if it is unknown.
+func (h *runCommandHandler) getMaxInputTokens(modelName string, models []*azuremodels.ModelSummary) int {
 	for _, model := range models {
-		if model.HasName(modelName) {
-			modelName = model.Name
-			foundMatch = true
-			break
+		if model.Name != modelName {
+			continue
 		}
+		details, err := h.client.GetModelDetails(h.ctx, model.RegistryName, model.Name, model.Version)
+		if err != nil {
+			// Without the details we can still chat, we just can't manage the context window.
+			return 0
+		}
+		return details.MaxInputTokens
 	}
+	return 0
+}
+
+// validateModelName returns the model with the given name, which can pin a version as "<model>@<version>". Only the
+// latest version of a model runs, so it returns an error if an older version is pinned.
+func validateModelName(modelName string, models []*azuremodels.ModelSummary) (*azuremodels.ModelSummary, error) {
+	noMatchErrorMessage := "The specified model name is not found. Run 'gh models list' to see available models or 'gh models run' to select interactively."
 
-	if !foundMatch {
-		return "", errors.New(noMatchErrorMessage)
+	modelName, version := azuremodels.SplitModelVersion(modelName)
+	if modelName == "" {
+		return nil, errors.New(noMatchErrorMessage)
 	}
 
-	return modelName, nil
+	model, err := azuremodels.FindModel(models, modelName)
+	var notFound *azuremodels.ModelNotFoundError
+	if errors.As(err, &notFound) {
+		if suggestion := notFound.Suggestion(); suggestion != "" {
+			return nil, fmt.Errorf("The specified model name is not found. %s Run 'gh models list' to see available models.", suggestion)
+		}
+		return nil, errors.New(noMatchErrorMessage)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return model, model.CheckVersion(version)
 }
 
-func (h *runCommandHandler) getChatCompletionStreamReader(req azuremodels.ChatCompletionOptions) (sse.Reader[azuremodels.ChatCompletion], error) {
+// getChatCompletionStream sends the request, and returns the streamed response and the model that answered.
+func (h *runCommandHandler) getChatCompletionStream(req azuremodels.ChatCompletionOptions) (sse.Reader[azuremodels.ChatCompletion], string, error) {
 	resp, err := h.client.GetChatCompletionStream(h.ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return resp.Reader, nil
+	model := resp.Model
+	if model == "" {
+		model = req.Model
+	}
+	return resp.Reader, model, nil
 }
 
-func (h *runCommandHandler) handleParametersPrompt(conversation Conversation, mp ModelParameters) {
+func (h *runCommandHandler) handleContinuePrompt(conversation *Conversation, window *contextWindow, modelName string, mp ModelParameters, opts responseOptions) error {
+	if len(conversation.messages) == 0 || conversation.messages[len(conversation.messages)-1].Role != azuremodels.ChatMessageRoleAssistant {
+		h.writeToOut("There is no response to continue\n")
+		return nil
+	}
+
+	out := newResponseWriter(h.cfg, opts)
+	result, err := h.continueCompletion(conversation, window, modelName, mp, out)
+	if err != nil {
+		return err
+	}
+
+	err = h.finishResponse(out, result, opts)
+	if err != nil {
+		util.WriteToOut(h.cfg.ErrOut, err.Error()+"\n")
+	}
+	return nil
+}
+
+func (h *runCommandHandler) handleParametersPrompt(conversation Conversation, mp *ModelParameters) {
 	h.writeToOut("Current parameters:\n")
-	names := []string{"max-tokens", "temperature", "top-p"}
-	for _, name := range names {
-		h.writeToOut(fmt.Sprintf("  %s: %s\n", name, mp.FormatParameter(name)))
+	for _, param := range modelParameters {
+		h.writeToOut(fmt.Sprintf("  %s: %s\n", param.name, mp.FormatParameter(param.name)))
+		h.writeToOut(fmt.Sprintf("    %s\n", param.description))
 	}
 	h.writeToOut("\n")
 	h.writeToOut("System Prompt:\n")
@@ -474,27 +657,151 @@ func (h *runCommandHandler) handleParametersPrompt(conversation Conversation, mp
 	}
 }
 
-func (h *runCommandHandler) handleResetPrompt(conversation Conversation) {
+func (h *runCommandHandler) handleResetPrompt(conversation *Conversation) {
 	conversation.Reset()
 	h.writeToOut("Reset chat history\n")
 }
 
-func (h *runCommandHandler) handleSetPrompt(prompt string, mp ModelParameters) {
-	parts := strings.Split(prompt, " ")
-	if len(parts) == 3 {
-		name := parts[1]
-		value := parts[2]
+func (h *runCommandHandler) handleTokensPrompt(conversation *Conversation, window *contextWindow) {
+	h.writeToOut("Context: " + window.usage(conversation) + "\n")
+	h.writeToOut("Strategy: " + string(window.strategy) + "\n")
+}
+
+func (h *runCommandHandler) fitContextWindow(conversation *Conversation, window *contextWindow, modelName string, mp ModelParameters) error {
+	summarize := func(messages []azuremodels.ChatMessage) (string, error) {
+		req := azuremodels.ChatCompletionOptions{Messages: messages, Model: modelName}
+		mp.UpdateRequest(&req)
+		req.N = nil
+		return h.getChatCompletion(req)
+	}
+
+	removed, err := window.fit(conversation, summarize)
+	if err != nil {
+		return err
+	}
+
+	if removed > 0 {
+		action := "Dropped"
+		if window.strategy == contextStrategySummarize {
+			action = "Summarized"
+		}
+		util.WriteToOut(h.cfg.ErrOut, fmt.Sprintf("%s %d older messages to fit the model's context window.\n", action, removed))
+	}
+
+	return nil
+}
+
+// getChatCompletion returns the full content of the model's response to the given request.
+func (h *runCommandHandler) getChatCompletion(req azuremodels.ChatCompletionOptions) (string, error) {
+	reader, _, err := h.getChatCompletionStream(req)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
 
-		err := mp.SetParameterByName(name, value)
+	sb := strings.Builder{}
+	for {
+		completion, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", err
+		}
+
+		for _, choice := range completion.Choices {
+			if choice.Delta != nil && choice.Delta.Content != nil {
+				sb.WriteString(*choice.Delta.Content)
+			} else if choice.Message != nil && choice.Message.Content != nil {
+				sb.WriteString(*choice.Message.Content)
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// handleSetPrompt sets a model parameter. Everything after the name is the value, so values such as stop sequences can
+// contain spaces, and can be quoted to keep leading or trailing spaces.
+func (h *runCommandHandler) handleSetPrompt(prompt string, mp *ModelParameters) {
+	name, value, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(prompt, "/set ")), " ")
+	value = strings.TrimSpace(value)
+	if !ok || name == "" || value == "" {
+		h.writeToOut("Invalid /set syntax. Usage: /set <name> <value>\n")
+		return
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil && strings.HasPrefix(value, `"`) {
+		value = unquoted
+	}
+
+	err := mp.SetParameterByName(name, value)
+	if err != nil {
+		h.writeToOut(err.Error() + "\n")
+		return
+	}
+
+	h.writeToOut("Set " + name + " to " + value + "\n")
+}
+
+func (h *runCommandHandler) handleSaveCodePrompt(prompt string, conversation *Conversation) {
+	args := strings.Fields(strings.TrimPrefix(prompt, "/save-code "))
+	if len(args) == 0 || len(args) > 2 {
+		h.writeToOut("Invalid /save-code syntax. Usage: /save-code <path> [index]\n")
+		return
+	}
+
+	selector := codeBlockSelector{}
+	if len(args) == 2 {
+		index, err := strconv.Atoi(args[1])
+		if err != nil || index < 1 {
+			h.writeToOut("Invalid code block index '" + args[1] + "'\n")
+			return
+		}
+		selector.index = index
+	}
+
+	blocks, err := selector.selectBlocks(extractCodeBlocks(conversation.lastAssistantMessage()))
+	if err != nil {
+		h.writeToOut(err.Error() + "\n")
+		return
+	}
+
+	block := blocks[0]
+	if len(blocks) > 1 {
+		options := make([]string, len(blocks))
+		for i, b := range blocks {
+			options[i] = fmt.Sprintf("%d. %s", i+1, b.description())
+		}
+		index := 0
+		err = survey.AskOne(&survey.Select{Message: "Select a code block:", Options: options}, &index, survey.WithPageSize(10))
 		if err != nil {
 			h.writeToOut(err.Error() + "\n")
 			return
 		}
+		block = blocks[index]
+	}
 
-		h.writeToOut("Set " + name + " to " + value + "\n")
-	} else {
-		h.writeToOut("Invalid /set syntax. Usage: /set <name> <value>\n")
+	path := args[0]
+	if _, err := os.Stat(path); err == nil {
+		overwrite := false
+		err = survey.AskOne(&survey.Confirm{Message: fmt.Sprintf("%s already exists. Overwrite it?", path)}, &overwrite)
+		if err != nil {
+			h.writeToOut(err.Error() + "\n")
+			return
+		}
+		if !overwrite {
+			h.writeToOut("Did not save code\n")
+			return
+		}
+	}
+
+	err = os.WriteFile(path, []byte(block.code), 0o644)
+	if err != nil {
+		h.writeToOut("Unable to save code: " + err.Error() + "\n")
+		return
 	}
+
+	h.writeToOut("Saved code to " + path + "\n")
 }
 
 func (h *runCommandHandler) handleSystemPrompt(prompt string, conversation Conversation) Conversation {
@@ -506,10 +813,13 @@ func (h *runCommandHandler) handleSystemPrompt(prompt string, conversation Conve
 func (h *runCommandHandler) handleHelpPrompt() {
 	h.writeToOut("Commands:\n")
 	h.writeToOut("  /bye, /exit, /quit - Exit the chat\n")
+	h.writeToOut("  /continue - Continue the last response, for example if it was cut off\n")
 	h.writeToOut("  /parameters - Show current model parameters\n")
 	h.writeToOut("  /reset, /clear - Reset chat context\n")
+	h.writeToOut("  /save-code <path> [index] - Save a code block from the last response to a file\n")
 	h.writeToOut("  /set <name> <value> - Set a model parameter\n")
 	h.writeToOut("  /system-prompt <prompt> - Set the system prompt\n")
+	h.writeToOut("  /tokens - Show how much of the model's context window is used\n")
 	h.writeToOut("  /help - Show this help message\n")
 }
 
@@ -517,7 +827,203 @@ func (h *runCommandHandler) handleUnrecognizedPrompt(prompt string) {
 	h.writeToOut("Unknown command '" + prompt + "'. See /help for supported commands.\n")
 }
 
-func (h *runCommandHandler) handleCompletionChoice(choice azuremodels.ChatChoice, messageBuilder strings.Builder) error {
+// completionResult is the model's response to a chat completion request.
+type completionResult struct {
+	// model is the model that answered, which is a fallback model if the requested model failed.
+	model        string
+	content      string
+	finishReason string
+	logprobs     []azuremodels.ChatTokenLogprob
+}
+
+// extend appends a continuation of the response.
+func (r *completionResult) extend(next *completionResult) {
+	r.model = next.model
+	r.content += next.content
+	r.finishReason = next.finishReason
+	r.logprobs = append(r.logprobs, next.logprobs...)
+}
+
+// continuePrompt asks the model to continue a response that was cut off.
+const continuePrompt = "Continue your previous response exactly where it stopped. Do not repeat anything, and do not add an introduction."
+
+// streamCompletion sends the request to the model, and writes the response to out as it streams in. If the request
+// asks for several candidate responses, they are returned in order without being written, since they stream in
+// interleaved.
+func (h *runCommandHandler) streamCompletion(req azuremodels.ChatCompletionOptions, out responseWriter) ([]*completionResult, error) {
+	sp := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(h.cfg.ErrOut))
+	sp.Start()
+	defer sp.Stop()
+
+	reader, model, err := h.getChatCompletionStream(req)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// Candidates are written once they are complete, so nothing is streamed when several are requested.
+	streamOut := out
+	candidates := req.N != nil && *req.N > 1
+	if candidates {
+		streamOut = discardResponseWriter{}
+	}
+
+	// Choices are accumulated by index, because streamed candidates arrive interleaved.
+	messageBuilders := map[int32]*strings.Builder{}
+	finishReasons := map[int32]string{}
+	logprobs := map[int32][]azuremodels.ChatTokenLogprob{}
+	indexes := []int32{}
+
+	for {
+		completion, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		sp.Stop()
+
+		for _, choice := range completion.Choices {
+			messageBuilder, ok := messageBuilders[choice.Index]
+			if !ok {
+				messageBuilder = &strings.Builder{}
+				messageBuilders[choice.Index] = messageBuilder
+				indexes = append(indexes, choice.Index)
+			}
+			if choice.FinishReason != "" {
+				finishReasons[choice.Index] = choice.FinishReason
+			}
+			if choice.Logprobs != nil {
+				logprobs[choice.Index] = append(logprobs[choice.Index], choice.Logprobs.Content...)
+			}
+			err = h.handleCompletionChoice(choice, messageBuilder, streamOut)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	slices.Sort(indexes)
+	results := make([]*completionResult, 0, max(len(indexes), 1))
+	for _, index := range indexes {
+		results = append(results, &completionResult{
+			model:        model,
+			content:      messageBuilders[index].String(),
+			finishReason: finishReasons[index],
+			logprobs:     logprobs[index],
+		})
+	}
+	if len(results) == 0 {
+		results = append(results, &completionResult{model: model})
+	}
+	if candidates && len(results) == 1 {
+		// Some providers ignore n, and return a single response, which is written like any other.
+		out.write(results[0].content)
+	}
+
+	return results, nil
+}
+
+// chooseCandidate writes each of the model's candidate responses, and returns the one to keep in the conversation. In
+// interactive mode the user picks the candidate, otherwise the first one is kept.
+func (h *runCommandHandler) chooseCandidate(candidates []*completionResult, opts responseOptions, interactive bool) (*completionResult, error) {
+	options := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		header := fmt.Sprintf("Candidate %d:", i+1)
+		if h.cfg.IsTerminalOutput {
+			header = candidateHeaderColor(header)
+		}
+		h.writeToOut(header + "\n")
+
+		out := newResponseWriter(h.cfg, opts)
+		out.write(candidate.content)
+		err := h.finishResponse(out, candidate, opts)
+		if err != nil {
+			util.WriteToOut(h.cfg.ErrOut, err.Error()+"\n")
+		}
+		if i < len(candidates)-1 {
+			h.writeToOut("\n")
+		}
+
+		firstLine, _, _ := strings.Cut(strings.TrimSpace(stripThinking(candidate.content)), "\n")
+		options[i] = fmt.Sprintf("%d. %s", i+1, text.Truncate(60, firstLine))
+	}
+
+	if !interactive {
+		return candidates[0], nil
+	}
+
+	index := 0
+	err := survey.AskOne(&survey.Select{
+		Message: "Which candidate should be kept in the conversation?",
+		Options: options,
+	}, &index, survey.WithPageSize(10))
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates[index], nil
+}
+
+// continueCompletion asks the model to continue its last response, writes the continuation to out, and appends it to
+// the last message in the conversation.
+func (h *runCommandHandler) continueCompletion(conversation *Conversation, window *contextWindow, modelName string, mp ModelParameters, out responseWriter) (*completionResult, error) {
+	err := h.fitContextWindow(conversation, window, modelName, mp)
+	if err != nil {
+		return nil, err
+	}
+
+	req := azuremodels.ChatCompletionOptions{
+		Messages: append(conversation.GetMessages(), azuremodels.ChatMessage{
+			Content: util.Ptr(continuePrompt),
+			Role:    azuremodels.ChatMessageRoleUser,
+		}),
+		Model: modelName,
+	}
+
+	mp.UpdateRequest(&req)
+
+	// Continue only the response that was kept, rather than generating new candidates.
+	req.N = nil
+
+	results, err := h.streamCompletion(req, out)
+	if err != nil {
+		return nil, err
+	}
+
+	result := results[0]
+	conversation.appendToLastMessage(stripThinking(result.content))
+	return result, nil
+}
+
+// finishResponse flushes the response, and warns if the model stopped before finishing it.
+func (h *runCommandHandler) finishResponse(out responseWriter, result *completionResult, opts responseOptions) error {
+	err := out.flush()
+
+	if !opts.codeOnly && !(opts.showTokens && !h.cfg.IsTerminalOutput) {
+		h.writeToOut("\n")
+	}
+
+	if opts.showTokens && err == nil {
+		if h.cfg.IsTerminalOutput {
+			h.writeToOut("\n")
+		}
+		err = h.writeTokens(result)
+	}
+
+	switch result.finishReason {
+	case azuremodels.FinishReasonLength:
+		util.WriteToOut(h.cfg.ErrOut, "Warning: the response was cut off because it reached the token limit. Use /continue or --auto-continue to continue it.\n")
+	case azuremodels.FinishReasonContentFilter:
+		util.WriteToOut(h.cfg.ErrOut, "Warning: the response was stopped by the content filter.\n")
+	}
+
+	return err
+}
+
+func (h *runCommandHandler) handleCompletionChoice(choice azuremodels.ChatChoice, messageBuilder *strings.Builder, out responseWriter) error {
 	// Streamed responses from the OpenAI API have their data in `.Delta`, while
 	// non-streamed responses use `.Message`, so let's support both
 	if choice.Delta != nil && choice.Delta.Content != nil {
@@ -526,14 +1032,14 @@ func (h *runCommandHandler) handleCompletionChoice(choice azuremodels.ChatChoice
 		if err != nil {
 			return err
 		}
-		h.writeToOut(*content)
+		out.write(*content)
 	} else if choice.Message != nil && choice.Message.Content != nil {
 		content := choice.Message.Content
 		_, err := messageBuilder.WriteString(*content)
 		if err != nil {
 			return err
 		}
-		h.writeToOut(*content)
+		out.write(*content)
 	}
 
 	// Introduce a small delay in between response tokens to better simulate a conversation
@@ -3,10 +3,14 @@ package run
 import (
 	"bytes"
 	"context"
+	"errors"
+	"net/http"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/quota"
 	"github.com/github/gh-models/internal/sse"
 	"github.com/github/gh-models/pkg/command"
 	"github.com/github/gh-models/pkg/util"
@@ -61,6 +65,517 @@ func TestRun(t *testing.T) {
 		require.Contains(t, output, fakeMessageFromModel)
 	})
 
+	t.Run("--code-only prints only the code from the response", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		modelSummary := &azuremodels.ModelSummary{
+			Name:         "test-model-1",
+			FriendlyName: "Test Model 1",
+			Task:         "chat-completion",
+		}
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{modelSummary}, nil
+		}
+		chatCompletion := azuremodels.ChatCompletion{Choices: []azuremodels.ChatChoice{{
+			Message: &azuremodels.ChatChoiceMessage{
+				Content: util.Ptr("Sure, here is a script:\n\n```sh\necho hello\n```\n\nRun it with sh."),
+				Role:    util.Ptr(string(azuremodels.ChatMessageRoleAssistant)),
+			},
+		}}}
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{chatCompletion}),
+			}, nil
+		}
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, errBuf, client, false, 80)
+		runCmd := NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"--code-only", modelSummary.Name, "write me a script"})
+
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "echo hello\n", outBuf.String())
+	})
+
+	t.Run("--auto-continue continues responses that were cut off", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		modelSummary := &azuremodels.ModelSummary{
+			Name:         "test-model-1",
+			FriendlyName: "Test Model 1",
+			Task:         "chat-completion",
+		}
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{modelSummary}, nil
+		}
+		responses := []azuremodels.ChatChoice{
+			{FinishReason: azuremodels.FinishReasonLength, Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("The quick brown ")}},
+			{FinishReason: azuremodels.FinishReasonStop, Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("fox jumps.")}},
+		}
+		requests := []azuremodels.ChatCompletionOptions{}
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			choice := responses[len(requests)]
+			requests = append(requests, opt)
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{{Choices: []azuremodels.ChatChoice{choice}}}),
+			}, nil
+		}
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, errBuf, client, false, 80)
+		runCmd := NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"--auto-continue", "2", modelSummary.Name, "finish this sentence"})
+
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "The quick brown fox jumps.\n", outBuf.String())
+		require.NotContains(t, errBuf.String(), "Warning")
+		require.Len(t, requests, 2)
+		continuation := requests[1].Messages
+		require.Equal(t, "The quick brown ", *continuation[len(continuation)-2].Content)
+		require.Equal(t, azuremodels.ChatMessageRoleAssistant, continuation[len(continuation)-2].Role)
+		require.Equal(t, continuePrompt, *continuation[len(continuation)-1].Content)
+	})
+
+	t.Run("warns when the response was cut off", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		modelSummary := &azuremodels.ModelSummary{
+			Name:         "test-model-1",
+			FriendlyName: "Test Model 1",
+			Task:         "chat-completion",
+		}
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{modelSummary}, nil
+		}
+		choice := azuremodels.ChatChoice{FinishReason: azuremodels.FinishReasonLength, Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("The quick brown ")}}
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{{Choices: []azuremodels.ChatChoice{choice}}}),
+			}, nil
+		}
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, errBuf, client, false, 80)
+		runCmd := NewRunCommand(cfg)
+		runCmd.SetArgs([]string{modelSummary.Name, "finish this sentence"})
+
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "The quick brown \n", outBuf.String())
+		require.Contains(t, errBuf.String(), "Warning: the response was cut off because it reached the token limit.")
+	})
+
+	t.Run("runs only the pinned version", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		modelSummary := &azuremodels.ModelSummary{
+			Name:         "gpt-4o",
+			FriendlyName: "GPT-4o",
+			Task:         "chat-completion",
+			Version:      "2024-08-06",
+			Labels:       []string{"latest", "deprecated"},
+		}
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{modelSummary}, nil
+		}
+		requestedModel := ""
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			requestedModel = opt.Model
+			choice := azuremodels.ChatChoice{Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("hi")}}
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{{Choices: []azuremodels.ChatChoice{choice}}}),
+			}, nil
+		}
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, errBuf, client, false, 80)
+
+		runCmd := NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"gpt-4o@2024-08-06", "hello"})
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "gpt-4o", requestedModel)
+		require.Contains(t, errBuf.String(), "Warning: gpt-4o is deprecated, and will be removed.")
+
+		runCmd = NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"gpt-4o@2024-05-13", "hello"})
+		_, err = runCmd.ExecuteC()
+
+		require.EqualError(t, err, "gpt-4o is pinned to version 2024-05-13, but version 2024-08-06 is the one that runs now. Update the pin to run it, or run 'gh models list --all-versions' to see the versions of each model")
+	})
+
+	t.Run("resolves close model names and suggests others", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{
+				{Name: "gpt-4o", FriendlyName: "OpenAI GPT-4o", Publisher: "OpenAI", Task: "chat-completion"},
+				{Name: "gpt-4o-mini", FriendlyName: "OpenAI GPT-4o mini", Publisher: "OpenAI", Task: "chat-completion"},
+			}, nil
+		}
+		requestedModel := ""
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			requestedModel = opt.Model
+			choice := azuremodels.ChatChoice{Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("hi")}}
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{{Choices: []azuremodels.ChatChoice{choice}}}),
+			}, nil
+		}
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, client, false, 80)
+
+		runCmd := NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"gpt4o", "hello"})
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "gpt-4o", requestedModel)
+
+		runCmd = NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"gpt-4o-mnii", "hello"})
+		_, err = runCmd.ExecuteC()
+
+		require.EqualError(t, err, "The specified model name is not found. Did you mean gpt-4o-mini? Run 'gh models list' to see available models.")
+	})
+
+	t.Run("--needs selects a model that meets the requirements", func(t *testing.T) {
+		t.Setenv("XDG_CACHE_HOME", t.TempDir())
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{
+				{Name: "gpt-4o", FriendlyName: "OpenAI GPT-4o", Task: "chat-completion", Popularity: 1},
+				{Name: "phi-4", FriendlyName: "Phi-4", Task: "chat-completion", Popularity: 2},
+			}, nil
+		}
+		client.MockGetModelDetails = func(ctx context.Context, registry, modelName, version string) (*azuremodels.ModelDetails, error) {
+			if modelName == "gpt-4o" {
+				return &azuremodels.ModelDetails{SupportedInputModalities: []string{"text", "image"}, MaxInputTokens: 131072}, nil
+			}
+			return &azuremodels.ModelDetails{SupportedInputModalities: []string{"text"}, MaxInputTokens: 16384}, nil
+		}
+		requestedModel := ""
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			requestedModel = opt.Model
+			choice := azuremodels.ChatChoice{Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("hi")}}
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{{Choices: []azuremodels.ChatChoice{choice}}}),
+			}, nil
+		}
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, errBuf, client, false, 80)
+
+		runCmd := NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"--needs", "vision", "--min-context", "64000", "describe", "this"})
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "gpt-4o", requestedModel)
+		require.Equal(t, "Selected gpt-4o from the models that accept images and have at least 64000 input tokens: it is the only model that meets the requirements.\n", errBuf.String())
+		require.Equal(t, "hi\n", outBuf.String())
+	})
+
+	t.Run("--fallback retries rate limited requests on the next model", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{
+				{Name: "gpt-4o", FriendlyName: "OpenAI GPT-4o", Task: "chat-completion"},
+				{Name: "gpt-4o-mini", FriendlyName: "OpenAI GPT-4o mini", Task: "chat-completion"},
+				{Name: "phi-4", FriendlyName: "Phi-4", Task: "chat-completion"},
+			}, nil
+		}
+		requestedModels := []string{}
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			requestedModels = append(requestedModels, opt.Model)
+			switch opt.Model {
+			case "gpt-4o":
+				return nil, &azuremodels.HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}
+			case "gpt-4o-mini":
+				return nil, &azuremodels.HTTPError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+			}
+			choice := azuremodels.ChatChoice{Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("hi")}}
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{{Choices: []azuremodels.ChatChoice{choice}}}),
+			}, nil
+		}
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, errBuf, client, false, 80)
+
+		runCmd := NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"--fallback", "gpt-4o-mini,phi-4", "gpt-4o", "hello"})
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"gpt-4o", "gpt-4o-mini", "phi-4"}, requestedModels)
+		require.Equal(t, "hi\n", outBuf.String())
+		require.Equal(t, "Warning: gpt-4o failed with 429 Too Many Requests, retrying on gpt-4o-mini.\nWarning: gpt-4o-mini failed with 503 Service Unavailable, retrying on phi-4.\n", errBuf.String())
+	})
+
+	t.Run("--fallback fits the conversation to the smallest context window of the models", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{
+				{Name: "gpt-4o", FriendlyName: "OpenAI GPT-4o", Task: "chat-completion"},
+				{Name: "phi-4", FriendlyName: "Phi-4", Task: "chat-completion"},
+			}, nil
+		}
+		client.MockGetModelDetails = func(ctx context.Context, registry, modelName, version string) (*azuremodels.ModelDetails, error) {
+			if modelName == "phi-4" {
+				return &azuremodels.ModelDetails{MaxInputTokens: 20}, nil
+			}
+			return &azuremodels.ModelDetails{MaxInputTokens: 128000}, nil
+		}
+		var requests []azuremodels.ChatCompletionOptions
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			requests = append(requests, opt)
+			if opt.Model == "gpt-4o" {
+				return nil, &azuremodels.HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}
+			}
+			choice := azuremodels.ChatChoice{Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("hi")}}
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{{Choices: []azuremodels.ChatChoice{choice}}}),
+			}, nil
+		}
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, errBuf, client, false, 80)
+
+		runCmd := NewRunCommand(cfg)
+		systemPrompt := strings.Repeat("You are a helpful assistant. ", 20)
+		runCmd.SetArgs([]string{"--fallback", "phi-4", "--context-strategy", "drop-oldest", "--system-prompt", systemPrompt, "gpt-4o", "hello"})
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Len(t, requests, 2)
+		require.Equal(t, "phi-4", requests[1].Model)
+		require.Len(t, requests[1].Messages, 1)
+		require.Equal(t, "hello", *requests[1].Messages[0].Content)
+		require.Equal(t, "hi\n", outBuf.String())
+	})
+
+	t.Run("providers whose models can't be listed only fail requests to their models", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{{Name: "gpt-4o", FriendlyName: "OpenAI GPT-4o", Task: "chat-completion"}}, nil
+		}
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			choice := azuremodels.ChatChoice{Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("hi")}}
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{{Choices: []azuremodels.ChatChoice{choice}}}),
+			}, nil
+		}
+		offline := azuremodels.NewMockClient()
+		offline.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return nil, errors.New("connection refused")
+		}
+		providerClient := azuremodels.NewProviderClient(client, map[string]azuremodels.Client{"ollama": offline}, nil)
+
+		outBuf := new(bytes.Buffer)
+		runCmd := NewRunCommand(command.NewConfig(outBuf, outBuf, providerClient, false, 80))
+		runCmd.SetArgs([]string{"gpt-4o", "hello"})
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "hi\n", outBuf.String())
+
+		runCmd = NewRunCommand(command.NewConfig(outBuf, outBuf, providerClient, false, 80))
+		runCmd.SetArgs([]string{"ollama:llama3:8b", "hello"})
+		_, err = runCmd.ExecuteC()
+
+		require.EqualError(t, err, "failed to list the models of the ollama provider: connection refused")
+	})
+
+	t.Run("--fallback retries requests over the budget of their tier on the next model", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{
+				{Name: "gpt-4o", FriendlyName: "OpenAI GPT-4o", Task: "chat-completion"},
+				{Name: "gpt-4o-mini", FriendlyName: "OpenAI GPT-4o mini", Task: "chat-completion"},
+			}, nil
+		}
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			if opt.Model == "gpt-4o" {
+				return nil, &quota.BudgetExceededError{Tier: "high", Limit: 50, Unit: "requests"}
+			}
+			choice := azuremodels.ChatChoice{Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("hi")}}
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{{Choices: []azuremodels.ChatChoice{choice}}}),
+			}, nil
+		}
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, errBuf, client, false, 80)
+
+		runCmd := NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"--fallback", "gpt-4o-mini", "gpt-4o", "hello"})
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "hi\n", outBuf.String())
+		require.Equal(t, "Warning: gpt-4o would exceed the daily budget of 50 requests for the high rate limit tier, retrying on gpt-4o-mini.\n", errBuf.String())
+	})
+
+	t.Run("/set takes everything after the name as the value", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		h := &runCommandHandler{cfg: command.NewConfig(buf, buf, nil, false, 80)}
+		mp := ModelParameters{}
+
+		h.handleSetPrompt("/set stop end of answer,STOP", &mp)
+		req := azuremodels.ChatCompletionOptions{}
+		mp.UpdateRequest(&req)
+		require.Equal(t, []string{"end of answer", "STOP"}, req.Stop)
+
+		h.handleSetPrompt(`/set stop " foo bar "`, &mp)
+		mp.UpdateRequest(&req)
+		require.Equal(t, []string{" foo bar "}, req.Stop)
+
+		h.handleSetPrompt("/set stop", &mp)
+		require.Equal(t, "Set stop to end of answer,STOP\nSet stop to  foo bar \nInvalid /set syntax. Usage: /set <name> <value>\n", buf.String())
+	})
+
+	t.Run("--n writes the response when only one candidate comes back", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{{Name: "test-model-1", FriendlyName: "Test Model 1", Task: "chat-completion"}}, nil
+		}
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			choice := azuremodels.ChatChoice{FinishReason: "stop", Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("the only answer")}}
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{{Choices: []azuremodels.ChatChoice{choice}}}),
+			}, nil
+		}
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		runCmd := NewRunCommand(command.NewConfig(outBuf, errBuf, client, false, 80))
+		runCmd.SetArgs([]string{"--n", "2", "test-model-1", "say something"})
+
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "the only answer\n", outBuf.String())
+	})
+
+	t.Run("--n writes each candidate separately", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		modelSummary := &azuremodels.ModelSummary{
+			Name:         "test-model-1",
+			FriendlyName: "Test Model 1",
+			Task:         "chat-completion",
+		}
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{modelSummary}, nil
+		}
+		// Candidates stream in interleaved, and not necessarily in order.
+		completions := []azuremodels.ChatCompletion{
+			{Choices: []azuremodels.ChatChoice{{Index: 1, Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("Second ")}}}},
+			{Choices: []azuremodels.ChatChoice{{Index: 0, Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("First ")}}}},
+			{Choices: []azuremodels.ChatChoice{{Index: 0, FinishReason: "stop", Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("answer")}}}},
+			{Choices: []azuremodels.ChatChoice{{Index: 1, FinishReason: "stop", Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("answer")}}}},
+		}
+		var request azuremodels.ChatCompletionOptions
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			request = opt
+			return &azuremodels.ChatCompletionResponse{Reader: sse.NewMockEventReader(completions)}, nil
+		}
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, errBuf, client, false, 80)
+		runCmd := NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"--n", "2", modelSummary.Name, "say something"})
+
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, 2, *request.N)
+		require.Equal(t, "Candidate 1:\nFirst answer\n\nCandidate 2:\nSecond answer\n", outBuf.String())
+	})
+
+	t.Run("--show-tokens writes the token probabilities as JSON", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		modelSummary := &azuremodels.ModelSummary{
+			Name:         "test-model-1",
+			FriendlyName: "Test Model 1",
+			Task:         "chat-completion",
+		}
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{modelSummary}, nil
+		}
+		completions := []azuremodels.ChatCompletion{{Choices: []azuremodels.ChatChoice{{
+			FinishReason: azuremodels.FinishReasonStop,
+			Message:      &azuremodels.ChatChoiceMessage{Content: util.Ptr("Yes")},
+			Logprobs: &azuremodels.ChatChoiceLogprobs{Content: []azuremodels.ChatTokenLogprob{{
+				TokenLogprob: azuremodels.TokenLogprob{Token: "Yes", Logprob: 0},
+				TopLogprobs:  []azuremodels.TokenLogprob{{Token: "Yes", Logprob: 0}},
+			}}},
+		}}}}
+		var request azuremodels.ChatCompletionOptions
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			request = opt
+			return &azuremodels.ChatCompletionResponse{Reader: sse.NewMockEventReader(completions)}, nil
+		}
+		outBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, new(bytes.Buffer), client, false, 80)
+		runCmd := NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"--show-tokens", modelSummary.Name, "is the sky blue?"})
+
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.True(t, *request.Logprobs)
+		require.Equal(t, defaultTopLogprobs, *request.TopLogprobs)
+		require.JSONEq(t, `{
+			"model": "test-model-1",
+			"content": "Yes",
+			"finish_reason": "stop",
+			"tokens": [{"token": "Yes", "logprob": 0, "probability": 1, "top_logprobs": [{"token": "Yes", "logprob": 0, "probability": 1}]}]
+		}`, outBuf.String())
+	})
+
+	t.Run("uses the default model, aliases and profile from the settings", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		models := []*azuremodels.ModelSummary{
+			{Name: "test-model-1", FriendlyName: "Test Model 1", Task: "chat-completion"},
+			{Name: "test-model-2", FriendlyName: "Test Model 2", Task: "chat-completion"},
+		}
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return models, nil
+		}
+		var request azuremodels.ChatCompletionOptions
+		client.MockGetChatCompletionStream = func(ctx context.Context, opt azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			request = opt
+			completion := azuremodels.ChatCompletion{Choices: []azuremodels.ChatChoice{{Message: &azuremodels.ChatChoiceMessage{Content: util.Ptr("ok")}}}}
+			return &azuremodels.ChatCompletionResponse{Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{completion})}, nil
+		}
+		cfg := command.NewConfig(new(bytes.Buffer), new(bytes.Buffer), client, false, 80)
+		require.NoError(t, cfg.Settings.Set("model", "fast"))
+		require.NoError(t, cfg.Settings.Set("aliases.fast", "test-model-1"))
+		require.NoError(t, cfg.Settings.Set("aliases.smart", "test-model-2"))
+		require.NoError(t, cfg.Settings.Set("parameters.temperature", "1"))
+		require.NoError(t, cfg.Settings.Set("profiles.triage.system-prompt", "Classify the issue."))
+		require.NoError(t, cfg.Settings.Set("profiles.triage.parameters.temperature", "0"))
+
+		runCmd := NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"--profile", "triage", "is this a bug?"})
+		_, err := runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "test-model-1", request.Model)
+		require.Equal(t, 0.0, *request.Temperature)
+		require.Equal(t, "Classify the issue.", *request.Messages[0].Content)
+		require.Equal(t, "is this a bug?", *request.Messages[1].Content)
+
+		runCmd = NewRunCommand(cfg)
+		runCmd.SetArgs([]string{"--temperature", "0.5", "smart", "is this a bug?"})
+		_, err = runCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "test-model-2", request.Model)
+		require.Equal(t, 0.5, *request.Temperature)
+		require.Len(t, request.Messages, 1)
+	})
+
 	t.Run("--help prints usage info", func(t *testing.T) {
 		outBuf := new(bytes.Buffer)
 		errBuf := new(bytes.Buffer)
@@ -0,0 +1,150 @@
+package run
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/github/gh-models/internal/tokens"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/github/gh-models/pkg/util"
+)
+
+// thinkingMode controls how the <think> sections written by reasoning models such as DeepSeek-R1 are displayed.
+type thinkingMode string
+
+const (
+	// thinkingModeShow writes thinking sections as the model returns them.
+	thinkingModeShow thinkingMode = "show"
+	// thinkingModeCollapse replaces thinking sections with a one-line summary when writing to a terminal.
+	thinkingModeCollapse thinkingMode = "collapse"
+	// thinkingModeHide removes thinking sections from the output.
+	thinkingModeHide thinkingMode = "hide"
+)
+
+const (
+	thinkStartTag = "<think>"
+	thinkEndTag   = "</think>"
+)
+
+var thinkSectionRegexp = regexp.MustCompile(`(?s)<think>.*?(</think>|$)`)
+
+func parseThinkingMode(value string) (thinkingMode, error) {
+	for _, mode := range []thinkingMode{thinkingModeShow, thinkingModeCollapse, thinkingModeHide} {
+		if strings.EqualFold(value, string(mode)) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown thinking mode '%s'. Supported modes: show, collapse, hide", value)
+}
+
+// stripThinking removes thinking sections from the given response, so they aren't sent back to the model as history.
+func stripThinking(content string) string {
+	if !strings.Contains(content, thinkStartTag) {
+		return content
+	}
+	return strings.TrimLeft(thinkSectionRegexp.ReplaceAllString(content, ""), "\n")
+}
+
+// thinkingResponseWriter removes thinking sections from the response before passing it on to another writer.
+type thinkingResponseWriter struct {
+	cfg   *command.Config
+	inner responseWriter
+	mode  thinkingMode
+	// buffer holds text that may be the start of a tag split across chunks.
+	buffer   string
+	thinking bool
+	thought  strings.Builder
+	// trimLeading is set after a thinking section, to drop the blank lines that separate it from the answer.
+	trimLeading bool
+}
+
+func newThinkingResponseWriter(cfg *command.Config, inner responseWriter, mode thinkingMode) responseWriter {
+	if mode != thinkingModeCollapse && mode != thinkingModeHide {
+		return inner
+	}
+	return &thinkingResponseWriter{cfg: cfg, inner: inner, mode: mode}
+}
+
+func (w *thinkingResponseWriter) write(content string) {
+	w.buffer += content
+
+	for {
+		tag := thinkStartTag
+		if w.thinking {
+			tag = thinkEndTag
+		}
+
+		if index := strings.Index(w.buffer, tag); index >= 0 {
+			w.emit(w.buffer[:index])
+			w.buffer = w.buffer[index+len(tag):]
+			w.toggle()
+			continue
+		}
+
+		keep := partialSuffixLength(w.buffer, tag)
+		w.emit(w.buffer[:len(w.buffer)-keep])
+		w.buffer = w.buffer[len(w.buffer)-keep:]
+		return
+	}
+}
+
+func (w *thinkingResponseWriter) flush() error {
+	w.emit(w.buffer)
+	w.buffer = ""
+	if w.thinking {
+		// The response ended before the thinking section did, for example because it was truncated.
+		w.toggle()
+	}
+	return w.inner.flush()
+}
+
+func (w *thinkingResponseWriter) emit(text string) {
+	if text == "" {
+		return
+	}
+
+	if w.thinking {
+		w.thought.WriteString(text)
+		return
+	}
+
+	if w.trimLeading {
+		text = strings.TrimLeft(text, "\n")
+		if text == "" {
+			return
+		}
+		w.trimLeading = false
+	}
+	w.inner.write(text)
+}
+
+func (w *thinkingResponseWriter) toggle() {
+	w.thinking = !w.thinking
+	collapse := w.mode == thinkingModeCollapse && w.cfg.IsTerminalOutput
+
+	if w.thinking {
+		w.thought.Reset()
+		if collapse {
+			util.WriteToOut(w.cfg.ErrOut, "Thinking...")
+		}
+		return
+	}
+
+	w.trimLeading = true
+	if collapse {
+		summary := fmt.Sprintf("Thought for ~%d tokens (use --thinking show to see the reasoning)\n", tokens.Estimate(w.thought.String()))
+		// Replace the "Thinking..." line with the summary.
+		util.WriteToOut(w.cfg.ErrOut, "\r\x1b[K"+summary)
+	}
+}
+
+// partialSuffixLength returns the length of the longest suffix of s that is a prefix of tag.
+func partialSuffixLength(s, tag string) int {
+	for n := min(len(s), len(tag)-1); n > 0; n-- {
+		if strings.HasSuffix(s, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
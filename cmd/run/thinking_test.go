@@ -0,0 +1,71 @@
+package run
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/github/gh-models/pkg/command"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThinking(t *testing.T) {
+	chunks := []string{"<thi", "nk>\nLet me", " think.\n</th", "ink>\n\nThe answer", " is 42. <b>bold</b>"}
+
+	t.Run("hide removes thinking sections, even when tags are split across chunks", func(t *testing.T) {
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, errBuf, nil, true, 80)
+		out := newResponseWriter(cfg, responseOptions{thinking: thinkingModeHide})
+
+		for _, chunk := range chunks {
+			out.write(chunk)
+		}
+		require.NoError(t, out.flush())
+
+		require.Equal(t, "The answer is 42. <b>bold</b>", outBuf.String())
+		require.Empty(t, errBuf.String())
+	})
+
+	t.Run("collapse summarizes thinking sections on the terminal", func(t *testing.T) {
+		outBuf := new(bytes.Buffer)
+		errBuf := new(bytes.Buffer)
+		cfg := command.NewConfig(outBuf, errBuf, nil, true, 80)
+		out := newResponseWriter(cfg, responseOptions{thinking: thinkingModeCollapse})
+
+		for _, chunk := range chunks {
+			out.write(chunk)
+		}
+		require.NoError(t, out.flush())
+
+		require.Equal(t, "The answer is 42. <b>bold</b>", outBuf.String())
+		require.Equal(t, "Thinking...\r\x1b[KThought for ~4 tokens (use --thinking show to see the reasoning)\n", errBuf.String())
+	})
+
+	t.Run("show writes thinking sections as-is", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, nil, true, 80)
+		out := newResponseWriter(cfg, responseOptions{thinking: thinkingModeShow})
+
+		for _, chunk := range chunks {
+			out.write(chunk)
+		}
+		require.NoError(t, out.flush())
+
+		require.Equal(t, "<think>\nLet me think.\n</think>\n\nThe answer is 42. <b>bold</b>", buf.String())
+	})
+
+	t.Run("stripThinking", func(t *testing.T) {
+		require.Equal(t, "no thinking here", stripThinking("no thinking here"))
+		require.Equal(t, "The answer.", stripThinking("<think>\nhmm\n</think>\n\nThe answer."))
+		require.Equal(t, "", stripThinking("<think>\ntruncated"))
+	})
+
+	t.Run("parseThinkingMode", func(t *testing.T) {
+		mode, err := parseThinkingMode("Hide")
+		require.NoError(t, err)
+		require.Equal(t, thinkingModeHide, mode)
+
+		_, err = parseThinkingMode("loud")
+		require.EqualError(t, err, "unknown thinking mode 'loud'. Supported modes: show, collapse, hide")
+	})
+}
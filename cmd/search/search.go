@@ -0,0 +1,115 @@
+// Package search provides a `gh models search` command to find models by what they can do.
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/github/gh-models/internal/catalog"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lightGrayUnderline = ansi.ColorFunc("white+du")
+)
+
+// NewSearchCommand returns a new command to search the models.
+func NewSearchCommand(cfg *command.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search the available models",
+		Long: heredoc.Docf(`
+			Searches the names, publishers, tags, summaries, supported languages and input types, and
+			descriptions of the available chat models, and lists the models that match, best matches first.
+
+			Narrow the results with %[1]s--publisher%[1]s, %[1]s--tag%[1]s, %[1]s--language%[1]s and %[1]s--input%[1]s,
+			which models must match exactly. Without a query, every model that passes the filters is listed.
+
+			The details of every model are cached for a day, so repeated searches don't fetch them again. Use
+			%[1]s--refresh%[1]s to fetch them now.
+		`, "`"),
+		Example: heredoc.Doc(`
+			gh models search small multilingual vision
+			gh models search --publisher meta --language Spanish
+			gh models search coding --input image --limit 3
+		`),
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+			query := catalog.Query{Text: strings.Join(args, " ")}
+			var err error
+			if query.Publisher, err = flags.GetString("publisher"); err != nil {
+				return err
+			}
+			if query.Tags, err = flags.GetStringArray("tag"); err != nil {
+				return err
+			}
+			if query.Language, err = flags.GetString("language"); err != nil {
+				return err
+			}
+			if query.InputType, err = flags.GetString("input"); err != nil {
+				return err
+			}
+			limit, err := flags.GetInt("limit")
+			if err != nil {
+				return err
+			}
+			refresh, err := flags.GetBool("refresh")
+			if err != nil {
+				return err
+			}
+
+			if strings.TrimSpace(query.Text) == "" && query.Publisher == "" && len(query.Tags) == 0 && query.Language == "" && query.InputType == "" {
+				return errors.New("specify a query or a filter to search for. Run 'gh models list' to see every model")
+			}
+
+			models, err := cfg.NewCatalogLoader().Load(cmd.Context(), refresh)
+			if err != nil {
+				return err
+			}
+
+			results := catalog.Search(models.ChatModels(), query)
+			if len(results) == 0 {
+				return errors.New("no models match the search. Try fewer words or filters")
+			}
+			if limit > 0 && len(results) > limit {
+				results = results[:limit]
+			}
+
+			if cfg.IsTerminalOutput {
+				cfg.WriteToOut("\n")
+				cfg.WriteToOut(fmt.Sprintf("Showing %d matching chat models\n", len(results)))
+				cfg.WriteToOut("\n")
+			}
+
+			printer := cfg.NewTablePrinter()
+
+			printer.AddHeader([]string{"DISPLAY NAME", "MODEL NAME", "PUBLISHER", "MATCHED"}, tableprinter.WithColor(lightGrayUnderline))
+			printer.EndRow()
+
+			for _, result := range results {
+				printer.AddField(result.Model.Summary.FriendlyName)
+				printer.AddField(result.Model.Summary.Name)
+				printer.AddField(result.Model.Summary.Publisher)
+				printer.AddField(strings.Join(result.Fields, ", "))
+				printer.EndRow()
+			}
+
+			return printer.Render()
+		},
+	}
+
+	cmd.Flags().String("publisher", "", "Only list models from this publisher.")
+	cmd.Flags().StringArray("tag", nil, "Only list models with this tag. Can be repeated.")
+	cmd.Flags().String("language", "", "Only list models that support this language, such as Spanish.")
+	cmd.Flags().String("input", "", "Only list models that accept this input type, such as image.")
+	cmd.Flags().Int("limit", 20, "The maximum number of models to list, or 0 for all of them.")
+	cmd.Flags().Bool("refresh", false, "Fetch the model details now, instead of using the cached details.")
+
+	return cmd
+}
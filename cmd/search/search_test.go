@@ -0,0 +1,49 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	t.Run("lists the matching models", func(t *testing.T) {
+		t.Setenv("XDG_CACHE_HOME", t.TempDir())
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{
+				{Name: "gpt-4o", FriendlyName: "OpenAI GPT-4o", Publisher: "OpenAI", Task: "chat-completion"},
+				{Name: "phi-4", FriendlyName: "Phi-4", Publisher: "Microsoft", Task: "chat-completion"},
+				{Name: "embed", FriendlyName: "Embeddings", Publisher: "OpenAI", Task: "embeddings"},
+			}, nil
+		}
+		client.MockGetModelDetails = func(ctx context.Context, registry, modelName, version string) (*azuremodels.ModelDetails, error) {
+			if modelName == "gpt-4o" {
+				return &azuremodels.ModelDetails{SupportedInputModalities: []string{"text", "image"}}, nil
+			}
+			return &azuremodels.ModelDetails{}, nil
+		}
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, client, false, 80)
+		searchCmd := NewSearchCommand(cfg)
+		searchCmd.SetArgs([]string{"image", "--publisher", "openai"})
+
+		_, err := searchCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "\nOpenAI GPT-4o\tgpt-4o\tOpenAI\tinput types\n", buf.String())
+	})
+
+	t.Run("requires a query or a filter", func(t *testing.T) {
+		searchCmd := NewSearchCommand(command.NewConfig(new(bytes.Buffer), new(bytes.Buffer), nil, false, 80))
+		searchCmd.SetArgs([]string{})
+
+		_, err := searchCmd.ExecuteC()
+
+		require.EqualError(t, err, "specify a query or a filter to search for. Run 'gh models list' to see every model")
+	})
+}
@@ -0,0 +1,137 @@
+// Package usage provides a `gh models usage` command to show the remaining daily budget of each rate limit tier.
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lightGrayUnderline = ansi.ColorFunc("white+du")
+	exhaustedColor     = ansi.ColorFunc("red")
+)
+
+// tierOutput is the budget and usage of a tier, as written by --json. Limits of 0 mean there is no limit, and the
+// remaining budget is -1.
+type tierOutput struct {
+	Tier              string `json:"tier"`
+	Requests          int    `json:"requests"`
+	RequestsPerDay    int    `json:"requests_per_day"`
+	RemainingRequests int    `json:"remaining_requests"`
+	Tokens            int    `json:"tokens"`
+	TokensPerDay      int    `json:"tokens_per_day"`
+	RemainingTokens   int    `json:"remaining_tokens"`
+}
+
+// NewUsageCommand returns a new command to show the remaining daily budget of each rate limit tier.
+func NewUsageCommand(cfg *command.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Show the remaining daily budget of each rate limit tier",
+		Long: heredoc.Docf(`
+			Shows the requests and tokens used today in each rate limit tier, and how much of the daily
+			budget of the tier is left. Budgets reset at midnight UTC.
+
+			Models are rate limited by tier, as shown by %[1]sgh models view%[1]s. Requests are recorded
+			locally, for each GitHub host, and a request that would exceed its tier's budget is refused before
+			it is sent. The budgets default to the limits of the free tiers, and can be changed with
+			%[1]sgh models config set budgets.<tier>.requests-per-day <limit>%[1]s and
+			%[1]sbudgets.<tier>.tokens-per-day%[1]s, where 0 means no limit.
+
+			Requests made elsewhere, such as from other machines, aren't counted.
+		`, "`"),
+		Example: heredoc.Doc(`
+			gh models usage
+			gh models config set budgets.low.requests-per-day 100
+		`),
+		Args: cobra.NoArgs,
+		Annotations: map[string]string{
+			command.AnnotationSkipClient: "true",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsonOutput, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			statuses, err := cfg.NewQuotaTracker().Status()
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				tiers := make([]tierOutput, len(statuses))
+				for i, status := range statuses {
+					tiers[i] = tierOutput{
+						Tier:              status.Tier,
+						Requests:          status.Used.Requests,
+						RequestsPerDay:    status.Budget.RequestsPerDay,
+						RemainingRequests: status.RemainingRequests(),
+						Tokens:            status.Used.Tokens,
+						TokensPerDay:      status.Budget.TokensPerDay,
+						RemainingTokens:   status.RemainingTokens(),
+					}
+				}
+				data, err := json.MarshalIndent(tiers, "", "  ")
+				if err != nil {
+					return err
+				}
+				cfg.WriteToOut(string(data) + "\n")
+				return nil
+			}
+
+			if cfg.IsTerminalOutput {
+				cfg.WriteToOut(fmt.Sprintf("\nUsage today. Budgets reset at midnight UTC, in %s.\n\n", untilMidnightUTC(time.Now())))
+			}
+
+			printer := cfg.NewTablePrinter()
+			printer.AddHeader([]string{"TIER", "REQUESTS", "REMAINING", "TOKENS", "REMAINING"}, tableprinter.WithColor(lightGrayUnderline))
+			printer.EndRow()
+
+			for _, status := range statuses {
+				printer.AddField(status.Tier)
+				addUsage(printer, status.Used.Requests, status.Budget.RequestsPerDay, status.RemainingRequests())
+				addUsage(printer, status.Used.Tokens, status.Budget.TokensPerDay, status.RemainingTokens())
+				printer.EndRow()
+			}
+
+			return printer.Render()
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Write the budgets and usage as JSON.")
+
+	return cmd
+}
+
+// addUsage adds the usage of a budget, as "used / limit", and what remains of it.
+func addUsage(printer tableprinter.TablePrinter, used, limit, remaining int) {
+	if limit == 0 {
+		printer.AddField(strconv.Itoa(used))
+		printer.AddField("no limit")
+		return
+	}
+
+	printer.AddField(fmt.Sprintf("%d / %d", used, limit))
+	if remaining == 0 {
+		printer.AddField("0", tableprinter.WithColor(exhaustedColor))
+	} else {
+		printer.AddField(strconv.Itoa(remaining))
+	}
+}
+
+// untilMidnightUTC returns the time until the budgets reset, rounded to minutes.
+func untilMidnightUTC(now time.Time) string {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	minutes := int(midnight.Sub(now).Minutes())
+	return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+}
@@ -0,0 +1,66 @@
+package usage
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage(t *testing.T) {
+	newConfig := func(t *testing.T, buf *bytes.Buffer) *command.Config {
+		t.Setenv("XDG_STATE_HOME", t.TempDir())
+		cfg := command.NewConfig(buf, buf, azuremodels.NewMockClient(), false, 80)
+		require.NoError(t, cfg.Settings.Set("budgets.custom.requests-per-day", "0"))
+		require.NoError(t, cfg.Settings.Set("budgets.low.tokens-per-day", "1000"))
+		return cfg
+	}
+
+	t.Run("lists the usage and remaining budget of each tier", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := newConfig(t, buf)
+		tracker := cfg.NewQuotaTracker()
+		for i := 0; i < 50; i++ {
+			_, err := tracker.Reserve("high", 10)
+			require.NoError(t, err)
+		}
+		_, err := tracker.Reserve("low", 250)
+		require.NoError(t, err)
+
+		cmd := NewUsageCommand(cfg)
+		_, err = cmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "\ncustom\t0\tno limit\t0\tno limit\nhigh\t50 / 50\t0\t500\tno limit\nlow\t1 / 150\t149\t250 / 1000\t750\n", buf.String())
+	})
+
+	t.Run("--json writes the budgets and usage", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := newConfig(t, buf)
+		_, err := cfg.NewQuotaTracker().Reserve("low", 250)
+		require.NoError(t, err)
+
+		cmd := NewUsageCommand(cfg)
+		cmd.SetArgs([]string{"--json"})
+		_, err = cmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), `{
+    "tier": "low",
+    "requests": 1,
+    "requests_per_day": 150,
+    "remaining_requests": 149,
+    "tokens": 250,
+    "tokens_per_day": 1000,
+    "remaining_tokens": 750
+  }`)
+		require.Contains(t, buf.String(), `"remaining_requests": -1`)
+	})
+
+	t.Run("untilMidnightUTC", func(t *testing.T) {
+		require.Equal(t, "2h 30m", untilMidnightUTC(time.Date(2024, 10, 1, 21, 30, 0, 0, time.UTC)))
+	})
+}
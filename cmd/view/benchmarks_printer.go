@@ -0,0 +1,83 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/github/gh-models/pkg/command"
+)
+
+// benchmarksPrinter renders the benchmark scores of one model, or of several models side by side.
+type benchmarksPrinter struct {
+	models  []*viewedModel
+	printer tableprinter.TablePrinter
+}
+
+func newBenchmarksPrinter(models []*viewedModel, cfg *command.Config) benchmarksPrinter {
+	return benchmarksPrinter{
+		models:  models,
+		printer: cfg.NewTablePrinter(),
+	}
+}
+
+func (p *benchmarksPrinter) render() error {
+	if len(p.models) == 1 {
+		return p.renderModel(p.models[0])
+	}
+
+	// The benchmarks are listed in the order they first appear, so benchmarks shared by the models come first.
+	names := []string{}
+	seen := map[string]bool{}
+	for _, model := range p.models {
+		for _, benchmark := range model.details.Benchmarks {
+			if !seen[benchmark.Name] {
+				seen[benchmark.Name] = true
+				names = append(names, benchmark.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return errors.New("the evaluations of these models have no benchmark scores")
+	}
+
+	header := []string{"BENCHMARK"}
+	for _, model := range p.models {
+		header = append(header, model.summary.FriendlyName)
+	}
+	p.printer.AddHeader(header, tableprinter.WithColor(lightGrayUnderline))
+	p.printer.EndRow()
+
+	for _, name := range names {
+		p.printer.AddField(name)
+		for _, model := range p.models {
+			benchmark, ok := model.details.Benchmark(name)
+			if ok {
+				p.printer.AddField(benchmark.FormatScore())
+			} else {
+				p.printer.AddField("-")
+			}
+		}
+		p.printer.EndRow()
+	}
+
+	return p.printer.Render()
+}
+
+func (p *benchmarksPrinter) renderModel(model *viewedModel) error {
+	if len(model.details.Benchmarks) == 0 {
+		return fmt.Errorf("the evaluation of %s has no benchmark scores", model.summary.Name)
+	}
+
+	p.printer.AddHeader([]string{"BENCHMARK", "METRIC", "SCORE"}, tableprinter.WithColor(lightGrayUnderline))
+	p.printer.EndRow()
+
+	for _, benchmark := range model.details.Benchmarks {
+		p.printer.AddField(benchmark.Name)
+		p.printer.AddField(benchmark.Metric)
+		p.printer.AddField(benchmark.FormatScore())
+		p.printer.EndRow()
+	}
+
+	return p.printer.Render()
+}
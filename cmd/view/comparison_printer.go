@@ -0,0 +1,96 @@
+package view
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/github/gh-models/pkg/command"
+	"github.com/mgutz/ansi"
+)
+
+var (
+	differenceColor = ansi.ColorFunc("yellow+b")
+)
+
+// comparisonRow is a row of the comparison table: a label, and how to describe each model in that row.
+type comparisonRow struct {
+	label string
+	value func(model *viewedModel) string
+	// highlight is true if differences between the models in this row should be highlighted.
+	highlight bool
+}
+
+var comparisonRows = []comparisonRow{
+	{"Model name:", func(m *viewedModel) string { return m.summary.Name }, false},
+	{"Version:", func(m *viewedModel) string { return versionDescription(m.summary, m.latestVersion) }, true},
+	{"Publisher:", func(m *viewedModel) string { return m.summary.Publisher }, true},
+	{"Input tokens:", func(m *viewedModel) string { return formatTokens(m.details.MaxInputTokens) }, true},
+	{"Output tokens:", func(m *viewedModel) string { return formatTokens(m.details.MaxOutputTokens) }, true},
+	{"Input types:", func(m *viewedModel) string { return strings.Join(m.details.SupportedInputModalities, ", ") }, true},
+	{"Output types:", func(m *viewedModel) string { return strings.Join(m.details.SupportedOutputModalities, ", ") }, true},
+	{"Languages:", func(m *viewedModel) string { return strings.Join(m.details.SupportedLanguages, ", ") }, true},
+	{"Rate limit tier:", func(m *viewedModel) string { return m.details.RateLimitTier }, true},
+	{"License:", func(m *viewedModel) string { return m.details.License }, true},
+	{"Tags:", func(m *viewedModel) string { return strings.Join(m.details.Tags, ", ") }, true},
+}
+
+// comparisonPrinter renders the details of several models side by side, one column per model, highlighting the rows
+// in which they differ.
+type comparisonPrinter struct {
+	models  []*viewedModel
+	printer tableprinter.TablePrinter
+}
+
+func newComparisonPrinter(models []*viewedModel, cfg *command.Config) comparisonPrinter {
+	return comparisonPrinter{
+		models:  models,
+		printer: cfg.NewTablePrinter(),
+	}
+}
+
+func (p *comparisonPrinter) render() error {
+	header := []string{""}
+	for _, model := range p.models {
+		header = append(header, model.summary.FriendlyName)
+	}
+	p.printer.AddHeader(header, tableprinter.WithColor(lightGrayUnderline))
+	p.printer.EndRow()
+
+	for _, row := range comparisonRows {
+		values := make([]string, len(p.models))
+		differs := false
+		for i, model := range p.models {
+			values[i] = row.value(model)
+			if values[i] != values[0] {
+				differs = true
+			}
+		}
+
+		color := lightGrayUnderline
+		if row.highlight && differs {
+			color = differenceColor
+		}
+		p.printer.AddField(row.label, tableprinter.WithTruncate(nil), tableprinter.WithColor(color))
+		for _, value := range values {
+			if value == "" {
+				value = "-"
+			}
+			if row.highlight && differs {
+				p.printer.AddField(value, tableprinter.WithColor(differenceColor))
+			} else {
+				p.printer.AddField(value)
+			}
+		}
+		p.printer.EndRow()
+	}
+
+	return p.printer.Render()
+}
+
+func formatTokens(tokens int) string {
+	if tokens == 0 {
+		return ""
+	}
+	return strconv.Itoa(tokens)
+}
@@ -17,14 +17,16 @@ var (
 type modelPrinter struct {
 	modelSummary  *azuremodels.ModelSummary
 	modelDetails  *azuremodels.ModelDetails
+	latestVersion string
 	printer       tableprinter.TablePrinter
 	terminalWidth int
 }
 
-func newModelPrinter(summary *azuremodels.ModelSummary, details *azuremodels.ModelDetails, cfg *command.Config) modelPrinter {
+func newModelPrinter(summary *azuremodels.ModelSummary, details *azuremodels.ModelDetails, latestVersion string, cfg *command.Config) modelPrinter {
 	return modelPrinter{
 		modelSummary:  summary,
 		modelDetails:  details,
+		latestVersion: latestVersion,
 		printer:       cfg.NewTablePrinter(),
 		terminalWidth: cfg.TerminalWidth,
 	}
@@ -35,6 +37,7 @@ func (p *modelPrinter) render() error {
 	if modelSummary != nil {
 		p.printLabelledLine("Display name:", modelSummary.FriendlyName)
 		p.printLabelledLine("Model name:", modelSummary.Name)
+		p.printLabelledLine("Version:", versionDescription(modelSummary, p.latestVersion))
 		p.printLabelledLine("Publisher:", modelSummary.Publisher)
 		p.printLabelledLine("Summary:", modelSummary.Summary)
 	}
@@ -62,6 +65,26 @@ func (p *modelPrinter) render() error {
 	return nil
 }
 
+// versionDescription describes the version of the model, and whether it's the latest version, which runs, or
+// deprecated.
+func versionDescription(summary *azuremodels.ModelSummary, latestVersion string) string {
+	version := summary.Version
+	if version == "" {
+		return ""
+	}
+
+	notes := []string{}
+	if latestVersion != "" && version != latestVersion {
+		notes = append(notes, "older version; the latest is "+latestVersion)
+	} else {
+		notes = append(notes, "latest")
+	}
+	if summary.IsDeprecated() {
+		notes = append(notes, "deprecated")
+	}
+	return version + " (" + strings.Join(notes, ", ") + ")"
+}
+
 func (p *modelPrinter) printLabelledLine(label, value string) {
 	if value == "" {
 		return
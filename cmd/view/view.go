@@ -2,9 +2,12 @@
 package view
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
-	"github.com/AlecAivazis/survey/v2"
 	"github.com/github/gh-models/internal/azuremodels"
 	"github.com/github/gh-models/pkg/command"
 	"github.com/MakeNowJust/heredoc"
@@ -14,7 +17,7 @@ import (
 // NewViewCommand returns a new command to view details about a model.
 func NewViewCommand(cfg *command.Config) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "view [model]",
+		Use:   "view [model]...",
 		Short: "View details about a model",
 		Long: heredoc.Docf(`
 			Returns details about the specified model.
@@ -24,55 +27,88 @@ func NewViewCommand(cfg *command.Config) *cobra.Command {
 
 			If you know which model you want information for, you can run the request in a single command
 			as %[1]sgh models view [model]%[1]s
+
+			To view an older version of a model, pin the version as %[1]s[model]@[version]%[1]s. Run
+			%[1]sgh models list --all-versions%[1]s to see the versions of each model.
+
+			To compare models, give more than one: %[1]sgh models view [model] [model]...%[1]s shows their
+			context limits, input and output types, languages, rate limit tier, license and tags side by side,
+			with the differences highlighted.
+
+			Use %[1]s--benchmarks%[1]s to list the benchmark scores from the evaluation of the models instead. To
+			rank all the models by a benchmark, run %[1]sgh models list --benchmark <name>%[1]s.
 		`, "`"),
-		Example: "gh models view gpt-4o",
+		Example: heredoc.Doc(`
+			gh models view gpt-4o
+			gh models view gpt-4o@2024-05-13
+			gh models view gpt-4o gpt-4o-mini Phi-4
+		`),
 		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			client := cfg.Client
+
+			showBenchmarks, err := cmd.Flags().GetBool("benchmarks")
+			if err != nil {
+				return err
+			}
+
+			sortOrder, err := cmd.Flags().GetString("sort")
+			if err != nil {
+				return err
+			}
+
 			models, err := client.ListModels(ctx)
 			if err != nil {
 				return err
 			}
 
-			azuremodels.SortModels(models)
+			if err := cfg.SortModels(ctx, models, sortOrder); err != nil {
+				return err
+			}
 
-			modelName := ""
+			modelNames := []string{}
 			switch {
 			case len(args) == 0:
 				// Need to prompt for a model
-				prompt := &survey.Select{
-					Message: "Select a model:",
-					Options: []string{},
+				picked, err := cfg.PickModel(ctx, models)
+				if err != nil {
+					return err
 				}
+				modelNames = append(modelNames, picked.Name)
 
-				for _, model := range models {
-					if !model.IsChatModel() {
-						continue
-					}
-					prompt.Options = append(prompt.Options, model.FriendlyName)
+			default:
+				for _, arg := range args {
+					modelNames = append(modelNames, cfg.Settings.ResolveAlias(arg))
 				}
+			}
 
-				err = survey.AskOne(prompt, &modelName, survey.WithPageSize(10))
+			resolver := &modelResolver{ctx: ctx, client: client, models: models}
+			viewed := make([]*viewedModel, len(modelNames))
+			for i, modelName := range modelNames {
+				viewed[i], err = resolver.resolve(modelName)
 				if err != nil {
 					return err
 				}
-
-			case len(args) >= 1:
-				modelName = args[0]
+				cfg.RememberModel(viewed[i].summary.Name)
 			}
 
-			modelSummary, err := getModelByName(modelName, models)
+			err = fetchDetails(ctx, client, viewed)
 			if err != nil {
 				return err
 			}
 
-			modelDetails, err := client.GetModelDetails(ctx, modelSummary.RegistryName, modelSummary.Name, modelSummary.Version)
-			if err != nil {
-				return err
+			if showBenchmarks {
+				benchmarksPrinter := newBenchmarksPrinter(viewed, cfg)
+				return benchmarksPrinter.render()
 			}
 
-			modelPrinter := newModelPrinter(modelSummary, modelDetails, cfg)
+			if len(viewed) > 1 {
+				comparisonPrinter := newComparisonPrinter(viewed, cfg)
+				return comparisonPrinter.render()
+			}
+
+			modelPrinter := newModelPrinter(viewed[0].summary, viewed[0].details, viewed[0].latestVersion, cfg)
 
 			err = modelPrinter.render()
 			if err != nil {
@@ -82,15 +118,93 @@ func NewViewCommand(cfg *command.Config) *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().Bool("benchmarks", false, "List the benchmark scores from the evaluation of the model.")
+	cmd.Flags().String("sort", "", "Sort the models to select from by name, popularity, publisher or context.")
+
 	return cmd
 }
 
+// viewedModel is a model to view, with its details once they have been fetched.
+type viewedModel struct {
+	summary       *azuremodels.ModelSummary
+	details       *azuremodels.ModelDetails
+	latestVersion string
+}
+
+// modelResolver finds the models to view, fetching every version of the models only if an older version is pinned.
+type modelResolver struct {
+	ctx      context.Context
+	client   azuremodels.Client
+	models   []*azuremodels.ModelSummary
+	versions []*azuremodels.ModelSummary
+}
+
+// resolve returns the model with the given name, which can pin a version as "<model>@<version>".
+func (r *modelResolver) resolve(modelName string) (*viewedModel, error) {
+	modelName, version := azuremodels.SplitModelVersion(modelName)
+	if err := azuremodels.CheckProvider(r.client, modelName); err != nil {
+		return nil, err
+	}
+	modelSummary, err := getModelByName(modelName, r.models)
+	if err != nil {
+		return nil, err
+	}
+
+	viewed := &viewedModel{summary: modelSummary, latestVersion: modelSummary.Version}
+	if modelSummary.CheckVersion(version) == nil {
+		return viewed, nil
+	}
+
+	if r.versions == nil {
+		r.versions, err = r.client.ListModelVersions(r.ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	viewed.summary, err = getModelVersion(modelSummary.Name, version, r.versions)
+	if err != nil {
+		return nil, err
+	}
+	return viewed, nil
+}
+
+// fetchDetails fetches the details of the given models concurrently.
+func fetchDetails(ctx context.Context, client azuremodels.Client, models []*viewedModel) error {
+	errs := make([]error, len(models))
+	var wg sync.WaitGroup
+	for i, model := range models {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			model.details, errs[i] = client.GetModelDetails(ctx, model.summary.RegistryName, model.summary.Name, model.summary.Version)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
 // getModelByName returns the model with the specified name, or an error if no such model exists within the given list.
+// Names that are close to, or part of, a model's name are matched as described by azuremodels.FindModel.
 func getModelByName(modelName string, models []*azuremodels.ModelSummary) (*azuremodels.ModelSummary, error) {
+	model, err := azuremodels.FindModel(models, modelName)
+	var notFound *azuremodels.ModelNotFoundError
+	if errors.As(err, &notFound) {
+		message := fmt.Sprintf("the specified model name is not supported: %s", modelName)
+		if suggestion := notFound.Suggestion(); suggestion != "" {
+			message += ". " + suggestion
+		}
+		return nil, errors.New(message)
+	}
+	return model, err
+}
+
+// getModelVersion returns the given version of the model, or an error if the model has no such version.
+func getModelVersion(modelName, version string, models []*azuremodels.ModelSummary) (*azuremodels.ModelSummary, error) {
 	for _, model := range models {
-		if model.HasName(modelName) {
+		if model.HasName(modelName) && strings.EqualFold(model.Version, version) {
 			return model, nil
 		}
 	}
-	return nil, fmt.Errorf("the specified model name is not supported: %s", modelName)
+	return nil, fmt.Errorf("%s has no version %s. Run 'gh models list --all-versions' to see the versions of each model", modelName, version)
 }
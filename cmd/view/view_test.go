@@ -87,6 +87,110 @@ func TestView(t *testing.T) {
 		require.Contains(t, output, modelDetails.Evaluation)
 	})
 
+	t.Run("views a pinned older version", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		latest := &azuremodels.ModelSummary{Name: "gpt-4o", FriendlyName: "GPT-4o", Task: "chat-completion", Version: "2024-08-06", Labels: []string{"latest"}}
+		older := &azuremodels.ModelSummary{Name: "gpt-4o", FriendlyName: "GPT-4o", Task: "chat-completion", Version: "2024-05-13", Labels: []string{"deprecated"}}
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{latest}, nil
+		}
+		client.MockListModelVersions = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{latest, older}, nil
+		}
+		requestedVersion := ""
+		client.MockGetModelDetails = func(ctx context.Context, registryName, modelName, version string) (*azuremodels.ModelDetails, error) {
+			requestedVersion = version
+			return &azuremodels.ModelDetails{}, nil
+		}
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, client, true, 120)
+		viewCmd := NewViewCommand(cfg)
+		viewCmd.SetArgs([]string{"gpt-4o@2024-05-13"})
+
+		_, err := viewCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "2024-05-13", requestedVersion)
+		require.Contains(t, buf.String(), "2024-05-13 (older version; the latest is 2024-08-06, deprecated)")
+
+		viewCmd = NewViewCommand(cfg)
+		viewCmd.SetArgs([]string{"gpt-4o@1999-01-01"})
+
+		_, err = viewCmd.ExecuteC()
+
+		require.EqualError(t, err, "gpt-4o has no version 1999-01-01. Run 'gh models list --all-versions' to see the versions of each model")
+	})
+
+	t.Run("compares several models side by side", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{
+				{Name: "gpt-4o", FriendlyName: "GPT-4o", Task: "chat-completion", Publisher: "OpenAI"},
+				{Name: "phi-4", FriendlyName: "Phi-4", Task: "chat-completion", Publisher: "Microsoft"},
+			}, nil
+		}
+		client.MockGetModelDetails = func(ctx context.Context, registryName, modelName, version string) (*azuremodels.ModelDetails, error) {
+			details := &azuremodels.ModelDetails{MaxInputTokens: 128000, MaxOutputTokens: 4096, License: "MIT"}
+			if modelName == "gpt-4o" {
+				details.MaxOutputTokens = 16384
+				details.License = "custom"
+				details.SupportedInputModalities = []string{"text", "image"}
+			}
+			return details, nil
+		}
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, client, false, 200)
+		cfg.Settings.Aliases = map[string]string{"small": "phi-4"}
+		viewCmd := NewViewCommand(cfg)
+		viewCmd.SetArgs([]string{"gpt-4o", "small"})
+
+		_, err := viewCmd.ExecuteC()
+
+		require.NoError(t, err)
+		output := buf.String()
+		require.Contains(t, output, "Model name:\tgpt-4o\tphi-4\n")
+		require.Contains(t, output, "Input tokens:\t128000\t128000\n")
+		require.Contains(t, output, "Output tokens:\t16384\t4096\n")
+		require.Contains(t, output, "Input types:\ttext, image\t-\n")
+		require.Contains(t, output, "License:\tcustom\tMIT\n")
+	})
+
+	t.Run("--benchmarks lists the benchmark scores", func(t *testing.T) {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{
+				{Name: "gpt-4o", FriendlyName: "GPT-4o", Task: "chat-completion"},
+				{Name: "phi-4", FriendlyName: "Phi-4", Task: "chat-completion"},
+			}, nil
+		}
+		client.MockGetModelDetails = func(ctx context.Context, registryName, modelName, version string) (*azuremodels.ModelDetails, error) {
+			if modelName == "phi-4" {
+				return &azuremodels.ModelDetails{Benchmarks: []azuremodels.Benchmark{{Name: "MMLU", Score: 84.8}}}, nil
+			}
+			return &azuremodels.ModelDetails{Benchmarks: []azuremodels.Benchmark{
+				{Name: "MMLU", Metric: "Accuracy", Score: 88.7},
+				{Name: "HumanEval", Metric: "pass@1", Score: 90.2},
+			}}, nil
+		}
+		buf := new(bytes.Buffer)
+		cfg := command.NewConfig(buf, buf, client, false, 200)
+
+		viewCmd := NewViewCommand(cfg)
+		viewCmd.SetArgs([]string{"--benchmarks", "gpt-4o"})
+		_, err := viewCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "\nMMLU\tAccuracy\t88.7\nHumanEval\tpass@1\t90.2\n", buf.String())
+
+		buf.Reset()
+		viewCmd = NewViewCommand(cfg)
+		viewCmd.SetArgs([]string{"--benchmarks", "gpt-4o", "phi-4"})
+		_, err = viewCmd.ExecuteC()
+
+		require.NoError(t, err)
+		require.Equal(t, "\nMMLU\t88.7\t84.8\nHumanEval\t90.2\t-\n", buf.String())
+	})
+
 	t.Run("--help prints usage info", func(t *testing.T) {
 		outBuf := new(bytes.Buffer)
 		errBuf := new(bytes.Buffer)
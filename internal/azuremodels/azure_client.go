@@ -5,7 +5,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,11 +25,15 @@ type AzureClient struct {
 
 // NewDefaultAzureClient returns a new Azure client using the given auth token using default API URLs.
 func NewDefaultAzureClient(authToken string) (*AzureClient, error) {
+	return NewDefaultAzureClientWithConfig(authToken, NewDefaultAzureClientConfig())
+}
+
+// NewDefaultAzureClientWithConfig returns a new Azure client using the given auth token and API URLs.
+func NewDefaultAzureClientWithConfig(authToken string, cfg *AzureClientConfig) (*AzureClient, error) {
 	httpClient, err := api.DefaultHTTPClient()
 	if err != nil {
 		return nil, err
 	}
-	cfg := NewDefaultAzureClientConfig()
 	return &AzureClient{client: httpClient, token: authToken, cfg: cfg}, nil
 }
 
@@ -41,12 +44,7 @@ func NewAzureClient(httpClient *http.Client, authToken string, cfg *AzureClientC
 
 // GetChatCompletionStream returns a stream of chat completions using the given options.
 func (c *AzureClient) GetChatCompletionStream(ctx context.Context, req ChatCompletionOptions) (*ChatCompletionResponse, error) {
-	// Check if the model name is `o1-mini` or `o1-preview`
-	if req.Model == "o1-mini" || req.Model == "o1-preview" {
-		req.Stream = false
-	} else {
-		req.Stream = true
-	}
+	GetModelCapabilities(req.Model).AdaptRequest(&req)
 
 	bodyBytes, err := json.Marshal(req)
 	if err != nil {
@@ -76,7 +74,7 @@ func (c *AzureClient) GetChatCompletionStream(ctx context.Context, req ChatCompl
 	if resp.StatusCode != http.StatusOK {
 		// If we aren't going to return an SSE stream, then ensure the response body is closed.
 		defer resp.Body.Close()
-		return nil, c.handleHTTPError(resp)
+		return nil, handleHTTPError(resp)
 	}
 
 	var chatCompletionResponse ChatCompletionResponse
@@ -116,7 +114,7 @@ func (c *AzureClient) GetModelDetails(ctx context.Context, registry, modelName,
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleHTTPError(resp)
+		return nil, handleHTTPError(resp)
 	}
 
 	decoder := json.NewDecoder(resp.Body)
@@ -135,6 +133,7 @@ func (c *AzureClient) GetModelDetails(ctx context.Context, registry, modelName,
 		Notes:              detailsResponse.Notes,
 		Tags:               lowercaseStrings(detailsResponse.Keywords),
 		Evaluation:         detailsResponse.Evaluation,
+		Benchmarks:         ParseBenchmarks(detailsResponse.Evaluation, modelName),
 	}
 
 	modelLimits := detailsResponse.ModelLimits
@@ -176,14 +175,27 @@ func lowercaseStrings(input []string) []string {
 	return output
 }
 
-// ListModels returns a list of available models.
+// ListModels returns a list of available models, in their latest versions.
 func (c *AzureClient) ListModels(ctx context.Context) ([]*ModelSummary, error) {
+	return c.searchModels(ctx, `
+		{ "field": "freePlayground", "values": ["true"], "operator": "eq"},
+		{ "field": "labels", "values": ["latest"], "operator": "eq"}
+	`)
+}
+
+// ListModelVersions returns a list of every version of the available models.
+func (c *AzureClient) ListModelVersions(ctx context.Context) ([]*ModelSummary, error) {
+	return c.searchModels(ctx, `
+		{ "field": "freePlayground", "values": ["true"], "operator": "eq"}
+	`)
+}
+
+// searchModels returns the models in the catalog that match the given filters, which are JSON objects separated by
+// commas.
+func (c *AzureClient) searchModels(ctx context.Context, filters string) ([]*ModelSummary, error) {
 	body := bytes.NewReader([]byte(`
 		{
-			"filters": [
-				{ "field": "freePlayground", "values": ["true"], "operator": "eq"},
-				{ "field": "labels", "values": ["latest"], "operator": "eq"}
-			],
+			"filters": [` + filters + `],
 			"order": [
 				{ "field": "displayName", "direction": "asc" }
 			]
@@ -205,7 +217,7 @@ func (c *AzureClient) ListModels(ctx context.Context) ([]*ModelSummary, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleHTTPError(resp)
+		return nil, handleHTTPError(resp)
 	}
 
 	decoder := json.NewDecoder(resp.Body)
@@ -224,6 +236,9 @@ func (c *AzureClient) ListModels(ctx context.Context) ([]*ModelSummary, error) {
 			inferenceTask = summary.InferenceTasks[0]
 		}
 
+		// The popularity is only used to sort models, so a missing or invalid popularity is just zero.
+		popularity, _ := summary.Popularity.Float64()
+
 		models = append(models, &ModelSummary{
 			ID:           summary.AssetID,
 			Name:         summary.Name,
@@ -233,53 +248,15 @@ func (c *AzureClient) ListModels(ctx context.Context) ([]*ModelSummary, error) {
 			Summary:      summary.Summary,
 			Version:      summary.Version,
 			RegistryName: summary.RegistryName,
+			Labels:       lowercaseStrings(summary.Labels),
+			Popularity:   popularity,
 		})
 	}
 
 	return models, nil
 }
 
-func (c *AzureClient) handleHTTPError(resp *http.Response) error {
-	sb := strings.Builder{}
-	var err error
-
-	switch resp.StatusCode {
-	case http.StatusUnauthorized:
-		_, err = sb.WriteString("unauthorized")
-		if err != nil {
-			return err
-		}
-
-	case http.StatusBadRequest:
-		_, err = sb.WriteString("bad request")
-		if err != nil {
-			return err
-		}
-
-	default:
-		_, err = sb.WriteString("unexpected response from the server: " + resp.Status)
-		if err != nil {
-			return err
-		}
-	}
-
+func handleHTTPError(resp *http.Response) error {
 	body, _ := io.ReadAll(resp.Body)
-	if len(body) > 0 {
-		_, err = sb.WriteString("\n")
-		if err != nil {
-			return err
-		}
-
-		_, err = sb.Write(body)
-		if err != nil {
-			return err
-		}
-
-		_, err = sb.WriteString("\n")
-		if err != nil {
-			return err
-		}
-	}
-
-	return errors.New(sb.String())
+	return &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Body: body}
 }
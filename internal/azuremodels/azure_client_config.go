@@ -1,9 +1,12 @@
 package azuremodels
 
+import "strings"
+
 const (
 	defaultInferenceURL     = "https://models.inference.ai.azure.com/chat/completions"
 	defaultAzureAiStudioURL = "https://api.catalog.azureml.ms"
-	defaultModelsURL        = defaultAzureAiStudioURL + "/asset-gallery/v1.0/models"
+	defaultModelsURL        = defaultAzureAiStudioURL + modelsPath
+	modelsPath              = "/asset-gallery/v1.0/models"
 )
 
 // AzureClientConfig represents configurable settings for the Azure client.
@@ -21,3 +24,27 @@ func NewDefaultAzureClientConfig() *AzureClientConfig {
 		ModelsURL:        defaultModelsURL,
 	}
 }
+
+// NewAzureClientConfig returns a new AzureClientConfig for the given inference URL and model catalog URL.
+func NewAzureClientConfig(inferenceURL, azureAiStudioURL string) *AzureClientConfig {
+	azureAiStudioURL = strings.TrimSuffix(azureAiStudioURL, "/")
+	return &AzureClientConfig{
+		InferenceURL:     inferenceURL,
+		AzureAiStudioURL: azureAiStudioURL,
+		ModelsURL:        azureAiStudioURL + modelsPath,
+	}
+}
+
+// hostAzureClientConfigs returns the API URLs for the GitHub hosts that they are built in for.
+var hostAzureClientConfigs = map[string]func() *AzureClientConfig{
+	"github.com": NewDefaultAzureClientConfig,
+}
+
+// NewAzureClientConfigForHost returns the API URLs for the given GitHub host, or false if they aren't built in.
+func NewAzureClientConfigForHost(host string) (*AzureClientConfig, bool) {
+	newConfig, ok := hostAzureClientConfigs[strings.ToLower(host)]
+	if !ok {
+		return nil, false
+	}
+	return newConfig(), true
+}
@@ -165,6 +165,44 @@ func TestAzureClient(t *testing.T) {
 			require.Equal(t, message2.Content, choicesReceived[1].Message.Content)
 		})
 
+		t.Run("decodes logprobs", func(t *testing.T) {
+			testServer := newTestServerForChatCompletion(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body ChatCompletionOptions
+				err := json.NewDecoder(r.Body).Decode(&body)
+				require.NoError(t, err)
+				require.True(t, *body.Logprobs)
+				require.Equal(t, 2, *body.TopLogprobs)
+
+				w.WriteHeader(http.StatusOK)
+				_, err = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"Yes"},` +
+					`"logprobs":{"content":[{"token":"Yes","logprob":-0.01,"bytes":[89,101,115],` +
+					`"top_logprobs":[{"token":"Yes","logprob":-0.01},{"token":"No","logprob":-4.6}]}]}}]}` + "\n\ndata: [DONE]\n"))
+				require.NoError(t, err)
+			}))
+			defer testServer.Close()
+			client := NewAzureClient(testServer.Client(), "fake-token-123abc", &AzureClientConfig{InferenceURL: testServer.URL})
+			opts := ChatCompletionOptions{
+				Model:       "some-test-model",
+				Logprobs:    util.Ptr(true),
+				TopLogprobs: util.Ptr(2),
+				Messages:    []ChatMessage{{Role: "user", Content: util.Ptr("Is the sky blue?")}},
+			}
+
+			resp, err := client.GetChatCompletionStream(ctx, opts)
+
+			require.NoError(t, err)
+			defer resp.Reader.Close()
+			completion, err := resp.Reader.Read()
+			require.NoError(t, err)
+			logprobs := completion.Choices[0].Logprobs
+			require.NotNil(t, logprobs)
+			require.Len(t, logprobs.Content, 1)
+			require.Equal(t, "Yes", logprobs.Content[0].Token)
+			require.Equal(t, -0.01, logprobs.Content[0].Logprob)
+			require.Equal(t, []int{89, 101, 115}, logprobs.Content[0].Bytes)
+			require.Equal(t, []TokenLogprob{{Token: "Yes", Logprob: -0.01}, {Token: "No", Logprob: -4.6}}, logprobs.Content[0].TopLogprobs)
+		})
+
 		t.Run("handles non-OK status", func(t *testing.T) {
 			errRespBody := `{"error": "o noes"}`
 			testServer := newTestServerForChatCompletion(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -256,6 +294,32 @@ func TestAzureClient(t *testing.T) {
 			require.Equal(t, summary2.RegistryName, models[1].RegistryName)
 		})
 
+		t.Run("ListModelVersions doesn't filter to the latest versions", func(t *testing.T) {
+			testServer := newTestServerForListModels(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Contains(t, string(body), "freePlayground")
+				require.NotContains(t, string(body), "latest")
+
+				_, err = w.Write([]byte(`{"summaries": [
+					{"name": "gpt-4o", "version": "2024-08-06", "labels": ["Latest"]},
+					{"name": "gpt-4o", "version": "2024-05-13", "labels": ["deprecated"]}
+				]}`))
+				require.NoError(t, err)
+			}))
+			defer testServer.Close()
+			cfg := &AzureClientConfig{ModelsURL: testServer.URL}
+			client := NewAzureClient(testServer.Client(), "fake-token-123abc", cfg)
+
+			models, err := client.ListModelVersions(ctx)
+
+			require.NoError(t, err)
+			require.Len(t, models, 2)
+			require.True(t, models[0].IsLatest())
+			require.False(t, models[1].IsLatest())
+			require.True(t, models[1].IsDeprecated())
+		})
+
 		t.Run("handles non-OK status", func(t *testing.T) {
 			errRespBody := `{"error": "o noes"}`
 			testServer := newTestServerForListModels(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,228 @@
+package azuremodels
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Benchmark is a score of a model in a benchmark, parsed from the tables in the model's evaluation.
+type Benchmark struct {
+	Name   string  `json:"name"`
+	Metric string  `json:"metric,omitempty"`
+	Score  float64 `json:"score"`
+}
+
+var (
+	markdownLinkPattern  = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	scorePattern         = regexp.MustCompile(`^[-+]?(\d+\.?\d*|\.\d+)`)
+	separatorCellPattern = regexp.MustCompile(`^:?-+:?$`)
+	nonAlphanumeric      = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// HasName returns true if the benchmark has the given name, ignoring case and punctuation, so that "mmlu pro"
+// matches "MMLU-Pro".
+func (b Benchmark) HasName(name string) bool {
+	return normalizeName(b.Name) == normalizeName(name)
+}
+
+// FormatScore returns the score without trailing zeros.
+func (b Benchmark) FormatScore() string {
+	return strconv.FormatFloat(b.Score, 'f', -1, 64)
+}
+
+// ParseBenchmarks returns the benchmark scores in the markdown tables of a model's evaluation. Evaluations often
+// compare the model with others, with a column of scores for each model, so the column that is named after the given
+// model is preferred, then a column named like "score", and then the first column of numbers.
+func ParseBenchmarks(evaluation, modelName string) []Benchmark {
+	benchmarks := []Benchmark{}
+	for _, table := range markdownTables(evaluation) {
+		benchmarks = append(benchmarks, table.benchmarks(modelName)...)
+	}
+	return benchmarks
+}
+
+// markdownTable is a table in markdown, with the cells of its header and of each row.
+type markdownTable struct {
+	header []string
+	rows   [][]string
+}
+
+// markdownTables returns the tables in the given markdown.
+func markdownTables(markdown string) []markdownTable {
+	tables := []markdownTable{}
+	lines := strings.Split(markdown, "\n")
+	for i := 0; i+1 < len(lines); i++ {
+		header, ok := tableCells(lines[i])
+		if !ok {
+			continue
+		}
+		separator, ok := tableCells(lines[i+1])
+		if !ok || !isSeparatorRow(separator) {
+			continue
+		}
+
+		table := markdownTable{header: header}
+		i += 2
+		for ; i < len(lines); i++ {
+			row, ok := tableCells(lines[i])
+			if !ok {
+				break
+			}
+			table.rows = append(table.rows, row)
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// tableCells returns the cells of a markdown table row, or false if the line isn't a table row.
+func tableCells(line string) ([]string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "|") {
+		return nil, false
+	}
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+	for i, cell := range cells {
+		cells[i] = cleanCell(cell)
+	}
+	return cells, true
+}
+
+func isSeparatorRow(cells []string) bool {
+	for _, cell := range cells {
+		if !separatorCellPattern.MatchString(strings.ReplaceAll(cell, " ", "")) {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanCell removes markdown formatting from a table cell.
+func cleanCell(cell string) string {
+	cell = markdownLinkPattern.ReplaceAllString(cell, "$1")
+	cell = strings.NewReplacer("**", "", "__", "", "`", "").Replace(cell)
+	return strings.TrimSpace(cell)
+}
+
+// modelColumn returns the column of the given model in a comparison table, or -1 if there isn't one. A column named
+// exactly after the model is preferred, and otherwise the column whose name shares the most with the model's name, so
+// that the scores of "gpt-4o-mini" aren't taken from a "GPT-4o" column next to its own.
+func (t markdownTable) modelColumn(modelName string, skip ...int) int {
+	model := normalizeName(modelName)
+	column, overlap, difference := -1, 0, 0
+	for i, header := range t.header {
+		header = normalizeName(header)
+		if slices.Contains(skip, i) || len(header) < 4 {
+			continue
+		}
+		if header == model {
+			return i
+		}
+
+		var shared int
+		switch {
+		case strings.Contains(header, model):
+			shared = len(model)
+		case strings.Contains(model, header):
+			shared = len(header)
+		default:
+			continue
+		}
+		diff := len(header) - len(model)
+		if diff < 0 {
+			diff = -diff
+		}
+		if shared > overlap || (shared == overlap && diff < difference) {
+			column, overlap, difference = i, shared, diff
+		}
+	}
+	return column
+}
+
+// benchmarks returns the benchmark scores in the table, or none if it doesn't look like a table of benchmarks.
+func (t markdownTable) benchmarks(modelName string) []Benchmark {
+	nameColumn := t.findColumn("benchmark", "dataset", "task", "eval")
+	if nameColumn < 0 {
+		nameColumn = 0
+	}
+	metricColumn := t.findColumn("metric")
+
+	scoreColumn := -1
+	if modelName != "" {
+		scoreColumn = t.modelColumn(modelName, nameColumn, metricColumn)
+	}
+	if scoreColumn < 0 {
+		scoreColumn = t.findColumn("score", "result", "accuracy")
+	}
+	if scoreColumn < 0 {
+		for i := range t.header {
+			if i != nameColumn && i != metricColumn && t.isNumericColumn(i) {
+				scoreColumn = i
+				break
+			}
+		}
+	}
+	if scoreColumn < 0 || scoreColumn == nameColumn {
+		return nil
+	}
+
+	benchmarks := []Benchmark{}
+	for _, row := range t.rows {
+		if nameColumn >= len(row) || scoreColumn >= len(row) || row[nameColumn] == "" {
+			continue
+		}
+		score, ok := parseScore(row[scoreColumn])
+		if !ok {
+			continue
+		}
+		benchmark := Benchmark{Name: row[nameColumn], Score: score}
+		if metricColumn >= 0 && metricColumn < len(row) {
+			benchmark.Metric = row[metricColumn]
+		}
+		benchmarks = append(benchmarks, benchmark)
+	}
+	return benchmarks
+}
+
+// findColumn returns the first column whose header contains one of the given words, or -1 if there is none.
+func (t markdownTable) findColumn(words ...string) int {
+	for i, header := range t.header {
+		header = strings.ToLower(header)
+		for _, word := range words {
+			if strings.Contains(header, word) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isNumericColumn returns true if most of the cells in the column are scores.
+func (t markdownTable) isNumericColumn(column int) bool {
+	numbers := 0
+	for _, row := range t.rows {
+		if column < len(row) {
+			if _, ok := parseScore(row[column]); ok {
+				numbers++
+			}
+		}
+	}
+	return numbers > 0 && numbers*2 >= len(t.rows)
+}
+
+// parseScore parses a score such as "88.7", "88.7%" or "88.7 (5-shot)".
+func parseScore(cell string) (float64, bool) {
+	match := scorePattern.FindString(strings.TrimSpace(cell))
+	if match == "" {
+		return 0, false
+	}
+	score, err := strconv.ParseFloat(match, 64)
+	return score, err == nil
+}
+
+func normalizeName(name string) string {
+	return nonAlphanumeric.ReplaceAllString(strings.ToLower(name), "")
+}
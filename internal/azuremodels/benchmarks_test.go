@@ -0,0 +1,81 @@
+package azuremodels
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBenchmarks(t *testing.T) {
+	t.Run("parses a table of scores", func(t *testing.T) {
+		evaluation := `The model was evaluated on:
+
+| Benchmark | Metric | Score |
+|-----------|:------:|------:|
+| **MMLU** | Accuracy | 88.7% |
+| [HumanEval](https://example.com) | pass@1 | 90.2 |
+| Notes | - | n/a |
+
+Some more text.`
+
+		benchmarks := ParseBenchmarks(evaluation, "gpt-4o")
+
+		require.Equal(t, []Benchmark{
+			{Name: "MMLU", Metric: "Accuracy", Score: 88.7},
+			{Name: "HumanEval", Metric: "pass@1", Score: 90.2},
+		}, benchmarks)
+	})
+
+	t.Run("prefers the column of the model in comparison tables", func(t *testing.T) {
+		evaluation := `| Category | Benchmark | Llama-3.1-8B | Phi-3.5-mini-instruct |
+| --- | --- | --- | --- |
+| Reasoning | ARC Challenge (10-shot) | 83.1 | 84.6 |
+| | MMLU-Pro | 44 | 47.4 |
+|Math|GSM8K|82.4|86.2|`
+
+		benchmarks := ParseBenchmarks(evaluation, "Phi-3.5-mini-instruct")
+
+		require.Equal(t, []Benchmark{
+			{Name: "ARC Challenge (10-shot)", Score: 84.6},
+			{Name: "MMLU-Pro", Score: 47.4},
+			{Name: "GSM8K", Score: 86.2},
+		}, benchmarks)
+	})
+
+	t.Run("takes the scores of the model rather than of a sibling model", func(t *testing.T) {
+		evaluation := `| Benchmark | GPT-4o | GPT-4o-mini | Claude 3 Haiku |
+| --- | --- | --- | --- |
+| MMLU | 88.7 | 82.0 | 75.2 |
+| HumanEval | 90.2 | 87.2 | 75.9 |`
+
+		require.Equal(t, []Benchmark{{Name: "MMLU", Score: 82.0}, {Name: "HumanEval", Score: 87.2}}, ParseBenchmarks(evaluation, "gpt-4o-mini"))
+		require.Equal(t, []Benchmark{{Name: "MMLU", Score: 88.7}, {Name: "HumanEval", Score: 90.2}}, ParseBenchmarks(evaluation, "gpt-4o"))
+
+		evaluation = `| Benchmark | GPT-4o (2024-05) | GPT-4o-mini (2024-07) |
+| --- | --- | --- |
+| MMLU | 88.7 | 82.0 |`
+
+		require.Equal(t, []Benchmark{{Name: "MMLU", Score: 82.0}}, ParseBenchmarks(evaluation, "gpt-4o-mini"))
+	})
+
+	t.Run("falls back to the first column of numbers", func(t *testing.T) {
+		evaluation := "| Eval | Ours | Theirs |\n|---|---|---|\n| MATH | 76.6 | 70.1 |"
+
+		require.Equal(t, []Benchmark{{Name: "MATH", Score: 76.6}}, ParseBenchmarks(evaluation, "other-model"))
+	})
+
+	t.Run("ignores text without tables", func(t *testing.T) {
+		require.Empty(t, ParseBenchmarks("| not a table\nScores are great.", "gpt-4o"))
+	})
+
+	t.Run("matches benchmark names ignoring case and punctuation", func(t *testing.T) {
+		details := &ModelDetails{Benchmarks: []Benchmark{{Name: "MMLU-Pro", Score: 47.4}}}
+
+		benchmark, ok := details.Benchmark("mmlu pro")
+		require.True(t, ok)
+		require.Equal(t, "47.4", benchmark.FormatScore())
+
+		_, ok = details.Benchmark("mmlu")
+		require.False(t, ok)
+	})
+}
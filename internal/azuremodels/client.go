@@ -8,6 +8,8 @@ type Client interface {
 	GetChatCompletionStream(context.Context, ChatCompletionOptions) (*ChatCompletionResponse, error)
 	// GetModelDetails returns the details of the specified model in a particular registry.
 	GetModelDetails(ctx context.Context, registry, modelName, version string) (*ModelDetails, error)
-	// ListModels returns a list of available models.
+	// ListModels returns a list of available models, in their latest versions.
 	ListModels(context.Context) ([]*ModelSummary, error)
+	// ListModelVersions returns a list of every version of the available models.
+	ListModelVersions(context.Context) ([]*ModelSummary, error)
 }
@@ -0,0 +1,81 @@
+package azuremodels
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// retryableError is implemented by errors after which a request may succeed on another model, such as an *HTTPError
+// for a rate limited model.
+type retryableError interface {
+	Retryable() bool
+}
+
+// FallbackClient retries chat completions on other models when the requested model is rate limited or the server
+// fails. Requests are retried before anything is streamed, since the error is in the response to the request.
+type FallbackClient struct {
+	client     Client
+	fallback   []string
+	onFallback func(from, to string, err error)
+}
+
+// NewFallbackClient returns a new client that sends chat completions to the given client, retrying them on the
+// fallback models in order. onFallback, if it isn't nil, is called before each retry with the model that failed, the
+// model the request is retried on, and the error.
+func NewFallbackClient(client Client, fallback []string, onFallback func(from, to string, err error)) *FallbackClient {
+	return &FallbackClient{client: client, fallback: fallback, onFallback: onFallback}
+}
+
+// GetChatCompletionStream returns a stream of chat completions from the requested model, or from the first fallback
+// model that answers. The response names the model that answered.
+func (c *FallbackClient) GetChatCompletionStream(ctx context.Context, req ChatCompletionOptions) (*ChatCompletionResponse, error) {
+	models := []string{req.Model}
+	for _, model := range c.fallback {
+		if !containsFoldString(models, model) {
+			models = append(models, model)
+		}
+	}
+
+	for i, model := range models {
+		req.Model = model
+		resp, err := c.client.GetChatCompletionStream(ctx, req)
+		if err == nil {
+			resp.Model = model
+			return resp, nil
+		}
+
+		var retryable retryableError
+		if i == len(models)-1 || !errors.As(err, &retryable) || !retryable.Retryable() {
+			return nil, err
+		}
+		if c.onFallback != nil {
+			c.onFallback(model, models[i+1], err)
+		}
+	}
+	return nil, errors.New("no models to send the request to")
+}
+
+// GetModelDetails returns the details of the specified model.
+func (c *FallbackClient) GetModelDetails(ctx context.Context, registry, modelName, version string) (*ModelDetails, error) {
+	return c.client.GetModelDetails(ctx, registry, modelName, version)
+}
+
+// ListModels returns a list of available models.
+func (c *FallbackClient) ListModels(ctx context.Context) ([]*ModelSummary, error) {
+	return c.client.ListModels(ctx)
+}
+
+// ListModelVersions returns every version of the available models.
+func (c *FallbackClient) ListModelVersions(ctx context.Context) ([]*ModelSummary, error) {
+	return c.client.ListModelVersions(ctx)
+}
+
+func containsFoldString(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package azuremodels
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/github/gh-models/internal/sse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackClient(t *testing.T) {
+	newClient := func(statuses map[string]int) (*MockClient, *[]string) {
+		requested := []string{}
+		client := NewMockClient()
+		client.MockGetChatCompletionStream = func(ctx context.Context, req ChatCompletionOptions) (*ChatCompletionResponse, error) {
+			requested = append(requested, req.Model)
+			if status, ok := statuses[req.Model]; ok {
+				return nil, &HTTPError{StatusCode: status, Status: http.StatusText(status)}
+			}
+			return &ChatCompletionResponse{Reader: sse.NewMockEventReader([]ChatCompletion{})}, nil
+		}
+		return client, &requested
+	}
+
+	t.Run("retries retryable errors on the fallback models", func(t *testing.T) {
+		client, requested := newClient(map[string]int{"gpt-4o": http.StatusTooManyRequests, "gpt-4o-mini": http.StatusInternalServerError})
+		fallbacks := [][2]string{}
+		fallbackClient := NewFallbackClient(client, []string{"gpt-4o-mini", "phi-4"}, func(from, to string, err error) {
+			fallbacks = append(fallbacks, [2]string{from, to})
+		})
+
+		resp, err := fallbackClient.GetChatCompletionStream(context.Background(), ChatCompletionOptions{Model: "gpt-4o"})
+
+		require.NoError(t, err)
+		require.Equal(t, "phi-4", resp.Model)
+		require.Equal(t, []string{"gpt-4o", "gpt-4o-mini", "phi-4"}, *requested)
+		require.Equal(t, [][2]string{{"gpt-4o", "gpt-4o-mini"}, {"gpt-4o-mini", "phi-4"}}, fallbacks)
+	})
+
+	t.Run("doesn't retry other errors", func(t *testing.T) {
+		client, requested := newClient(map[string]int{"gpt-4o": http.StatusBadRequest})
+		fallbackClient := NewFallbackClient(client, []string{"gpt-4o-mini"}, nil)
+
+		_, err := fallbackClient.GetChatCompletionStream(context.Background(), ChatCompletionOptions{Model: "gpt-4o"})
+
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		require.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+		require.Equal(t, []string{"gpt-4o"}, *requested)
+	})
+
+	t.Run("returns the last error when every model fails", func(t *testing.T) {
+		client, requested := newClient(map[string]int{"gpt-4o": http.StatusTooManyRequests, "gpt-4o-mini": http.StatusTooManyRequests})
+		fallbackClient := NewFallbackClient(client, []string{"GPT-4o", "gpt-4o-mini"}, nil)
+
+		_, err := fallbackClient.GetChatCompletionStream(context.Background(), ChatCompletionOptions{Model: "gpt-4o"})
+
+		require.EqualError(t, err, "unexpected response from the server: Too Many Requests")
+		require.Equal(t, []string{"gpt-4o", "gpt-4o-mini"}, *requested)
+	})
+}
@@ -0,0 +1,42 @@
+package azuremodels
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HTTPError is an unsuccessful response from a models API.
+type HTTPError struct {
+	StatusCode int
+	// Status is the status line, such as "429 Too Many Requests".
+	Status string
+	// Body is the body of the response, which usually explains the error.
+	Body []byte
+}
+
+func (e *HTTPError) Error() string {
+	sb := strings.Builder{}
+
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		sb.WriteString("unauthorized")
+	case http.StatusBadRequest:
+		sb.WriteString("bad request")
+	default:
+		sb.WriteString("unexpected response from the server: " + e.Status)
+	}
+
+	if len(e.Body) > 0 {
+		sb.WriteString("\n")
+		sb.Write(e.Body)
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// Retryable returns true if the request may succeed on another model, or later: when the model is rate limited, or
+// the server failed.
+func (e *HTTPError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
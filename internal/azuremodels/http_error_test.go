@@ -0,0 +1,23 @@
+package azuremodels
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPError(t *testing.T) {
+	t.Run("describes the response", func(t *testing.T) {
+		require.Equal(t, "unauthorized", (&HTTPError{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized"}).Error())
+		require.Equal(t, "bad request\n{\"error\":\"no\"}\n", (&HTTPError{StatusCode: http.StatusBadRequest, Body: []byte(`{"error":"no"}`)}).Error())
+		require.Equal(t, "unexpected response from the server: 429 Too Many Requests", (&HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}).Error())
+	})
+
+	t.Run("Retryable", func(t *testing.T) {
+		require.True(t, (&HTTPError{StatusCode: http.StatusTooManyRequests}).Retryable())
+		require.True(t, (&HTTPError{StatusCode: http.StatusBadGateway}).Retryable())
+		require.False(t, (&HTTPError{StatusCode: http.StatusBadRequest}).Retryable())
+		require.False(t, (&HTTPError{StatusCode: http.StatusUnauthorized}).Retryable())
+	})
+}
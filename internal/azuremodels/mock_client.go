@@ -10,6 +10,7 @@ type MockClient struct {
 	MockGetChatCompletionStream func(context.Context, ChatCompletionOptions) (*ChatCompletionResponse, error)
 	MockGetModelDetails         func(context.Context, string, string, string) (*ModelDetails, error)
 	MockListModels              func(context.Context) ([]*ModelSummary, error)
+	MockListModelVersions       func(context.Context) ([]*ModelSummary, error)
 }
 
 // NewMockClient returns a new mock client for stubbing out interactions with the models API.
@@ -24,6 +25,9 @@ func NewMockClient() *MockClient {
 		MockListModels: func(context.Context) ([]*ModelSummary, error) {
 			return nil, errors.New("ListModels not implemented")
 		},
+		MockListModelVersions: func(context.Context) ([]*ModelSummary, error) {
+			return nil, errors.New("ListModelVersions not implemented")
+		},
 	}
 }
 
@@ -41,3 +45,8 @@ func (c *MockClient) GetModelDetails(ctx context.Context, registry, modelName, v
 func (c *MockClient) ListModels(ctx context.Context) ([]*ModelSummary, error) {
 	return c.MockListModels(ctx)
 }
+
+// ListModelVersions calls the mocked function for getting a list of every version of the available models.
+func (c *MockClient) ListModelVersions(ctx context.Context) ([]*ModelSummary, error) {
+	return c.MockListModelVersions(ctx)
+}
@@ -0,0 +1,104 @@
+package azuremodels
+
+import "strings"
+
+// ModelCapabilities describes which chat completion features a model supports, so requests can be adapted to it.
+type ModelCapabilities struct {
+	// SupportsStreaming is true if the model can stream its response.
+	SupportsStreaming bool
+	// SupportsSystemRole is true if the model accepts messages with the system role.
+	SupportsSystemRole bool
+	// SupportsSampling is true if the model accepts the temperature and top_p options.
+	SupportsSampling bool
+	// UsesMaxCompletionTokens is true if the model expects max_completion_tokens instead of max_tokens.
+	UsesMaxCompletionTokens bool
+	// SupportsReasoningEffort is true if the model accepts the reasoning_effort option.
+	SupportsReasoningEffort bool
+	// SupportsLogprobs is true if the model can return the log probabilities of the tokens in its response.
+	SupportsLogprobs bool
+}
+
+var defaultModelCapabilities = ModelCapabilities{
+	SupportsStreaming:  true,
+	SupportsSystemRole: true,
+	SupportsSampling:   true,
+	SupportsLogprobs:   true,
+}
+
+// modelCapabilities lists the models that don't support the default capabilities, keyed by lowercase model name.
+var modelCapabilities = map[string]ModelCapabilities{
+	"o1": {
+		SupportsStreaming:       false,
+		SupportsSystemRole:      true,
+		SupportsSampling:        false,
+		UsesMaxCompletionTokens: true,
+		SupportsReasoningEffort: true,
+	},
+	"o1-mini": {
+		SupportsStreaming:       false,
+		SupportsSystemRole:      false,
+		SupportsSampling:        false,
+		UsesMaxCompletionTokens: true,
+		SupportsReasoningEffort: false,
+	},
+	"o1-preview": {
+		SupportsStreaming:       false,
+		SupportsSystemRole:      false,
+		SupportsSampling:        false,
+		UsesMaxCompletionTokens: true,
+		SupportsReasoningEffort: false,
+	},
+	"o3-mini": {
+		SupportsStreaming:       true,
+		SupportsSystemRole:      true,
+		SupportsSampling:        false,
+		UsesMaxCompletionTokens: true,
+		SupportsReasoningEffort: true,
+	},
+}
+
+// GetModelCapabilities returns the capabilities of the model with the given name.
+func GetModelCapabilities(modelName string) ModelCapabilities {
+	if capabilities, ok := modelCapabilities[strings.ToLower(modelName)]; ok {
+		return capabilities
+	}
+	return defaultModelCapabilities
+}
+
+// AdaptRequest adapts the given request to what the model supports: it turns streaming on or off, renames or drops
+// options the model rejects, and sends system messages as user messages if the model doesn't support the system role.
+func (c ModelCapabilities) AdaptRequest(req *ChatCompletionOptions) {
+	req.Stream = c.SupportsStreaming
+
+	if c.UsesMaxCompletionTokens {
+		if req.MaxCompletionTokens == nil {
+			req.MaxCompletionTokens = req.MaxTokens
+		}
+		req.MaxTokens = nil
+	}
+
+	if !c.SupportsSampling {
+		req.Temperature = nil
+		req.TopP = nil
+	}
+
+	if !c.SupportsReasoningEffort {
+		req.ReasoningEffort = nil
+	}
+
+	if !c.SupportsLogprobs {
+		req.Logprobs = nil
+		req.TopLogprobs = nil
+	}
+
+	if !c.SupportsSystemRole {
+		messages := make([]ChatMessage, len(req.Messages))
+		for i, message := range req.Messages {
+			if message.Role == ChatMessageRoleSystem {
+				message.Role = ChatMessageRoleUser
+			}
+			messages[i] = message
+		}
+		req.Messages = messages
+	}
+}
@@ -0,0 +1,68 @@
+package azuremodels
+
+import (
+	"testing"
+
+	"github.com/github/gh-models/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelCapabilities(t *testing.T) {
+	t.Run("GetModelCapabilities", func(t *testing.T) {
+		require.Equal(t, defaultModelCapabilities, GetModelCapabilities("gpt-4o"))
+		require.Equal(t, defaultModelCapabilities, GetModelCapabilities("DeepSeek-R1"))
+		require.False(t, GetModelCapabilities("O1-Mini").SupportsStreaming)
+		require.True(t, GetModelCapabilities("o3-mini").SupportsReasoningEffort)
+	})
+
+	t.Run("AdaptRequest leaves requests for default models alone", func(t *testing.T) {
+		req := ChatCompletionOptions{
+			MaxTokens:       util.Ptr(100),
+			Temperature:     util.Ptr(0.5),
+			ReasoningEffort: util.Ptr("high"),
+			Logprobs:        util.Ptr(true),
+			TopLogprobs:     util.Ptr(3),
+			Messages:        []ChatMessage{{Role: ChatMessageRoleSystem, Content: util.Ptr("be brief")}},
+		}
+
+		GetModelCapabilities("gpt-4o").AdaptRequest(&req)
+
+		require.True(t, req.Stream)
+		require.Equal(t, 100, *req.MaxTokens)
+		require.Nil(t, req.MaxCompletionTokens)
+		require.Equal(t, 0.5, *req.Temperature)
+		require.Nil(t, req.ReasoningEffort)
+		require.True(t, *req.Logprobs)
+		require.Equal(t, 3, *req.TopLogprobs)
+		require.Equal(t, ChatMessageRoleSystem, req.Messages[0].Role)
+	})
+
+	t.Run("AdaptRequest adapts requests for reasoning models", func(t *testing.T) {
+		messages := []ChatMessage{
+			{Role: ChatMessageRoleSystem, Content: util.Ptr("be brief")},
+			{Role: ChatMessageRoleUser, Content: util.Ptr("hello")},
+		}
+		req := ChatCompletionOptions{
+			MaxTokens:       util.Ptr(100),
+			Temperature:     util.Ptr(0.5),
+			TopP:            util.Ptr(0.9),
+			ReasoningEffort: util.Ptr("high"),
+			Logprobs:        util.Ptr(true),
+			TopLogprobs:     util.Ptr(3),
+			Messages:        messages,
+		}
+
+		GetModelCapabilities("o1-mini").AdaptRequest(&req)
+
+		require.False(t, req.Stream)
+		require.Nil(t, req.MaxTokens)
+		require.Equal(t, 100, *req.MaxCompletionTokens)
+		require.Nil(t, req.Temperature)
+		require.Nil(t, req.TopP)
+		require.Nil(t, req.ReasoningEffort)
+		require.Nil(t, req.Logprobs)
+		require.Nil(t, req.TopLogprobs)
+		require.Equal(t, ChatMessageRoleUser, req.Messages[0].Role)
+		require.Equal(t, ChatMessageRoleSystem, messages[0].Role, "the caller's messages are not modified")
+	})
+}
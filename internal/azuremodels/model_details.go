@@ -4,18 +4,29 @@ import "fmt"
 
 // ModelDetails includes detailed information about a model.
 type ModelDetails struct {
-	Description               string   `json:"description"`
-	Evaluation                string   `json:"evaluation"`
-	License                   string   `json:"license"`
-	LicenseDescription        string   `json:"license_description"`
-	Notes                     string   `json:"notes"`
-	Tags                      []string `json:"tags"`
-	SupportedInputModalities  []string `json:"supported_input_modalities"`
-	SupportedOutputModalities []string `json:"supported_output_modalities"`
-	SupportedLanguages        []string `json:"supported_languages"`
-	MaxOutputTokens           int      `json:"max_output_tokens"`
-	MaxInputTokens            int      `json:"max_input_tokens"`
-	RateLimitTier             string   `json:"rateLimitTier"`
+	Description               string      `json:"description"`
+	Evaluation                string      `json:"evaluation"`
+	License                   string      `json:"license"`
+	LicenseDescription        string      `json:"license_description"`
+	Notes                     string      `json:"notes"`
+	Tags                      []string    `json:"tags"`
+	SupportedInputModalities  []string    `json:"supported_input_modalities"`
+	SupportedOutputModalities []string    `json:"supported_output_modalities"`
+	SupportedLanguages        []string    `json:"supported_languages"`
+	MaxOutputTokens           int         `json:"max_output_tokens"`
+	MaxInputTokens            int         `json:"max_input_tokens"`
+	RateLimitTier             string      `json:"rateLimitTier"`
+	Benchmarks                []Benchmark `json:"benchmarks,omitempty"`
+}
+
+// Benchmark returns the model's score in the benchmark with the given name, or false if it has none.
+func (m *ModelDetails) Benchmark(name string) (Benchmark, bool) {
+	for _, benchmark := range m.Benchmarks {
+		if benchmark.HasName(name) {
+			return benchmark, true
+		}
+	}
+	return Benchmark{}, false
 }
 
 // ContextLimits returns a summary of the context limits for the model.
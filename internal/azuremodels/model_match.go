@@ -0,0 +1,184 @@
+package azuremodels
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// minPartialNameLength is the length a name must have to be matched as part of a model's name, so that names
+	// like "4" don't match most models.
+	minPartialNameLength = 3
+	// maxListedModels is the number of models listed in the errors of FindModel.
+	maxListedModels = 5
+)
+
+// ModelNotFoundError is returned by FindModel when no model has the given name. Suggestions lists the names of the
+// models with the closest names, if any are close.
+type ModelNotFoundError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *ModelNotFoundError) Error() string {
+	message := fmt.Sprintf("the model '%s' is not found", e.Name)
+	if suggestion := e.Suggestion(); suggestion != "" {
+		message += ". " + suggestion
+	}
+	return message
+}
+
+// Suggestion returns a question suggesting the closest model names, or "" if there are none.
+func (e *ModelNotFoundError) Suggestion() string {
+	switch len(e.Suggestions) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("Did you mean %s?", e.Suggestions[0])
+	default:
+		return fmt.Sprintf("Did you mean one of: %s?", listNames(e.Suggestions))
+	}
+}
+
+// AmbiguousModelError is returned by FindModel when the given name is part of the names of several models.
+type AmbiguousModelError struct {
+	Name       string
+	Candidates []string
+}
+
+func (e *AmbiguousModelError) Error() string {
+	return fmt.Sprintf("'%s' matches several models: %s. Use the name of one of them", e.Name, listNames(e.Candidates))
+}
+
+// FindModel returns the model with the given name or friendly name. The name can be qualified with the publisher, as
+// in "openai/gpt-4o". If no model has exactly that name, ignoring case, the name is matched ignoring punctuation, so
+// that "gpt4o" finds "gpt-4o", and then as part of a model's name, so that "4o-mini" finds "gpt-4o-mini" if no other
+// model's name contains it. Otherwise, it returns an *AmbiguousModelError if several models match, or a
+// *ModelNotFoundError suggesting the models with the closest names.
+func FindModel(models []*ModelSummary, name string) (*ModelSummary, error) {
+	candidates := models
+	query := name
+	if publisher, modelName, ok := strings.Cut(name, "/"); ok && modelName != "" {
+		if publisherModels := filterByPublisher(models, publisher); len(publisherModels) > 0 {
+			candidates, query = publisherModels, modelName
+		}
+	}
+
+	for _, model := range candidates {
+		if model.HasName(query) {
+			return model, nil
+		}
+	}
+
+	normalized := normalizeName(query)
+	if normalized == "" {
+		return nil, &ModelNotFoundError{Name: name}
+	}
+
+	matches := matchModels(candidates, func(modelName string) bool { return modelName == normalized })
+	if len(matches) == 0 && len(normalized) >= minPartialNameLength {
+		matches = matchModels(candidates, func(modelName string) bool { return strings.Contains(modelName, normalized) })
+	}
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1:
+		return nil, &AmbiguousModelError{Name: name, Candidates: modelNames(matches)}
+	}
+
+	return nil, &ModelNotFoundError{Name: name, Suggestions: modelNames(closestModels(candidates, normalized))}
+}
+
+// filterByPublisher returns the models whose publisher or registry matches the given publisher, ignoring case and
+// punctuation, so that "openai" matches the publisher "OpenAI" and the registry "azure-openai".
+func filterByPublisher(models []*ModelSummary, publisher string) []*ModelSummary {
+	publisher = normalizeName(publisher)
+	if publisher == "" {
+		return nil
+	}
+	matches := []*ModelSummary{}
+	for _, model := range models {
+		if strings.Contains(normalizeName(model.Publisher), publisher) || strings.Contains(normalizeName(model.RegistryName), publisher) {
+			matches = append(matches, model)
+		}
+	}
+	return matches
+}
+
+// matchModels returns the models for which match returns true for their normalized name or friendly name, leaving
+// out other versions of models that already matched.
+func matchModels(models []*ModelSummary, match func(modelName string) bool) []*ModelSummary {
+	matches := []*ModelSummary{}
+	seen := map[string]bool{}
+	for _, model := range models {
+		if seen[model.Name] {
+			continue
+		}
+		if match(normalizeName(model.Name)) || match(normalizeName(model.FriendlyName)) {
+			seen[model.Name] = true
+			matches = append(matches, model)
+		}
+	}
+	return matches
+}
+
+// closestModels returns the models whose names are the fewest edits away from the given normalized name, if they
+// are close enough to be a typo: at most one edit for every three characters.
+func closestModels(models []*ModelSummary, normalized string) []*ModelSummary {
+	maxDistance := max(1, len(normalized)/3)
+	closest := []*ModelSummary{}
+	closestDistance := maxDistance + 1
+	for _, model := range models {
+		distance := min(editDistance(normalized, normalizeName(model.Name)), editDistance(normalized, normalizeName(model.FriendlyName)))
+		switch {
+		case distance < closestDistance:
+			closest, closestDistance = []*ModelSummary{model}, distance
+		case distance == closestDistance:
+			closest = append(closest, model)
+		}
+	}
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between two strings: the number of characters that must be inserted,
+// deleted or substituted to turn one into the other.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func modelNames(models []*ModelSummary) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, model := range models {
+		if !seen[model.Name] {
+			seen[model.Name] = true
+			names = append(names, model.Name)
+		}
+	}
+	return names
+}
+
+// listNames joins the given names, leaving out all but the first few.
+func listNames(names []string) string {
+	if len(names) <= maxListedModels {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:maxListedModels], ", "), len(names)-maxListedModels)
+}
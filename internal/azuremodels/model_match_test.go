@@ -0,0 +1,73 @@
+package azuremodels
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindModel(t *testing.T) {
+	models := []*ModelSummary{
+		{Name: "gpt-4o", FriendlyName: "OpenAI GPT-4o", Publisher: "OpenAI", RegistryName: "azure-openai"},
+		{Name: "gpt-4o-mini", FriendlyName: "OpenAI GPT-4o mini", Publisher: "OpenAI", RegistryName: "azure-openai"},
+		{Name: "Phi-4", FriendlyName: "Phi-4", Publisher: "Microsoft", RegistryName: "azureml"},
+		{Name: "Phi-4-mini-instruct", FriendlyName: "Phi-4-mini-instruct", Publisher: "Microsoft", RegistryName: "azureml"},
+	}
+
+	find := func(t *testing.T, name string) string {
+		model, err := FindModel(models, name)
+		require.NoError(t, err)
+		return model.Name
+	}
+
+	t.Run("matches names exactly, ignoring case", func(t *testing.T) {
+		require.Equal(t, "gpt-4o", find(t, "GPT-4O"))
+		require.Equal(t, "gpt-4o-mini", find(t, "OpenAI GPT-4o mini"))
+	})
+
+	t.Run("matches names ignoring punctuation", func(t *testing.T) {
+		require.Equal(t, "gpt-4o", find(t, "gpt4o"))
+		require.Equal(t, "Phi-4", find(t, "phi 4"))
+	})
+
+	t.Run("matches names qualified with the publisher", func(t *testing.T) {
+		require.Equal(t, "gpt-4o", find(t, "openai/gpt-4o"))
+		require.Equal(t, "Phi-4", find(t, "microsoft/phi4"))
+	})
+
+	t.Run("matches unambiguous partial names", func(t *testing.T) {
+		require.Equal(t, "gpt-4o-mini", find(t, "4o-mini"))
+		require.Equal(t, "Phi-4-mini-instruct", find(t, "instruct"))
+	})
+
+	t.Run("lists the candidates for ambiguous partial names", func(t *testing.T) {
+		_, err := FindModel(models, "mini")
+
+		var ambiguous *AmbiguousModelError
+		require.True(t, errors.As(err, &ambiguous))
+		require.Equal(t, []string{"gpt-4o-mini", "Phi-4-mini-instruct"}, ambiguous.Candidates)
+		require.EqualError(t, err, "'mini' matches several models: gpt-4o-mini, Phi-4-mini-instruct. Use the name of one of them")
+	})
+
+	t.Run("suggests the closest names for typos", func(t *testing.T) {
+		_, err := FindModel(models, "gtp-4o")
+		require.EqualError(t, err, "the model 'gtp-4o' is not found. Did you mean gpt-4o?")
+
+		_, err = FindModel(models, "phi-5")
+		require.EqualError(t, err, "the model 'phi-5' is not found. Did you mean Phi-4?")
+
+		_, err = FindModel(models, "llama-3")
+		var notFound *ModelNotFoundError
+		require.True(t, errors.As(err, &notFound))
+		require.Empty(t, notFound.Suggestions)
+		require.EqualError(t, err, "the model 'llama-3' is not found")
+	})
+
+	t.Run("editDistance", func(t *testing.T) {
+		require.Equal(t, 0, editDistance("gpt4o", "gpt4o"))
+		require.Equal(t, 2, editDistance("gtp4o", "gpt4o"))
+		require.Equal(t, 3, editDistance("kitten", "sitting"))
+		require.Equal(t, 5, editDistance("", "gpt4o"))
+	})
+}
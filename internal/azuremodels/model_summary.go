@@ -1,21 +1,28 @@
 package azuremodels
 
 import (
+	"fmt"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 )
 
+// VersionSeparator separates the model from a pinned version in model references, such as "gpt-4o@2024-08-06".
+const VersionSeparator = "@"
+
 // ModelSummary includes basic information about a model.
 type ModelSummary struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	FriendlyName string `json:"friendly_name"`
-	Task         string `json:"task"`
-	Publisher    string `json:"publisher"`
-	Summary      string `json:"summary"`
-	Version      string `json:"version"`
-	RegistryName string `json:"registry_name"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	FriendlyName string   `json:"friendly_name"`
+	Task         string   `json:"task"`
+	Publisher    string   `json:"publisher"`
+	Summary      string   `json:"summary"`
+	Version      string   `json:"version"`
+	RegistryName string   `json:"registry_name"`
+	Labels       []string `json:"labels,omitempty"`
+	Popularity   float64  `json:"popularity,omitempty"`
 }
 
 // IsChatModel returns true if the model is for chat completions.
@@ -28,23 +35,106 @@ func (m *ModelSummary) HasName(name string) bool {
 	return strings.EqualFold(m.FriendlyName, name) || strings.EqualFold(m.Name, name)
 }
 
-var (
-	featuredModelNames = []string{}
+// IsLatest returns true if this is the latest version of the model, which is the version that runs.
+func (m *ModelSummary) IsLatest() bool {
+	return slices.Contains(m.Labels, "latest")
+}
+
+// IsDeprecated returns true if the model version is deprecated, and will be removed.
+func (m *ModelSummary) IsDeprecated() bool {
+	return slices.Contains(m.Labels, "deprecated")
+}
+
+// VersionedName returns the name of the model pinned to its version, such as "gpt-4o@2024-08-06".
+func (m *ModelSummary) VersionedName() string {
+	if m.Version == "" {
+		return m.Name
+	}
+	return m.Name + VersionSeparator + m.Version
+}
+
+// CheckVersion returns an error if the given pinned version is not the version of the model, so that a pinned model
+// never silently runs a different version. An empty version isn't pinned.
+func (m *ModelSummary) CheckVersion(version string) error {
+	if version == "" || strings.EqualFold(version, m.Version) {
+		return nil
+	}
+	return fmt.Errorf("%s is pinned to version %s, but version %s is the one that runs now. Update the pin to run it, or run 'gh models list --all-versions' to see the versions of each model", m.Name, version, m.Version)
+}
+
+// SplitModelVersion splits a model reference such as "gpt-4o@2024-08-06" into the model name and the pinned version.
+// The version is empty if the reference isn't pinned.
+func SplitModelVersion(ref string) (string, string) {
+	if index := strings.LastIndex(ref, VersionSeparator); index > 0 {
+		return ref[:index], ref[index+1:]
+	}
+	return ref, ""
+}
+
+// Orders in which models can be sorted.
+const (
+	SortByName       = "name"
+	SortByPopularity = "popularity"
+	SortByPublisher  = "publisher"
+	SortByContext    = "context"
 )
 
-// SortModels sorts the given models in place, with featured models first, and then by friendly name.
+// SortOrders lists the orders in which models can be sorted.
+var SortOrders = []string{SortByName, SortByPopularity, SortByPublisher, SortByContext}
+
+// ValidateSortOrder returns an error if the given order is not one of SortOrders.
+func ValidateSortOrder(order string) error {
+	if !slices.Contains(SortOrders, order) {
+		return fmt.Errorf("invalid sort order '%s'. Use one of: %s", order, strings.Join(SortOrders, ", "))
+	}
+	return nil
+}
+
+// ModelOrder describes how to sort models: pinned models first, then featured models, each in the order they are
+// given, and then the other models in the order given by By.
+type ModelOrder struct {
+	// By is one of SortOrders. Models are sorted by name if it is empty.
+	By string
+	// Pinned lists the names of the models to show first.
+	Pinned []string
+	// Featured lists the names of the models to show after the pinned models.
+	Featured []string
+	// ContextSize returns the input token limit of a model. It's needed to sort by context.
+	ContextSize func(*ModelSummary) int
+}
+
+// SortModels sorts the given models in place by friendly name, with the newest versions first.
 func SortModels(models []*ModelSummary) {
-	sort.Slice(models, func(i, j int) bool {
-		// Sort featured models first, by name
-		isFeaturedI := slices.Contains(featuredModelNames, models[i].Name)
-		isFeaturedJ := slices.Contains(featuredModelNames, models[j].Name)
+	ModelOrder{}.Sort(models)
+}
 
-		if isFeaturedI && !isFeaturedJ {
-			return true
+// Sort sorts the given models in place. Models with the same position in the order are sorted by friendly name, and
+// then with the newest versions first.
+func (o ModelOrder) Sort(models []*ModelSummary) {
+	sort.Slice(models, func(i, j int) bool {
+		// Sort pinned models first, then featured models, in the order they are listed
+		rankI, rankJ := o.rank(models[i]), o.rank(models[j])
+		if rankI != rankJ {
+			return rankI < rankJ
 		}
 
-		if !isFeaturedI && isFeaturedJ {
-			return false
+		switch o.By {
+		case SortByPopularity:
+			if models[i].Popularity != models[j].Popularity {
+				return models[i].Popularity > models[j].Popularity
+			}
+		case SortByPublisher:
+			publisherI, publisherJ := strings.ToLower(models[i].Publisher), strings.ToLower(models[j].Publisher)
+			if publisherI != publisherJ {
+				return publisherI < publisherJ
+			}
+		case SortByContext:
+			if o.ContextSize != nil {
+				contextI, contextJ := o.ContextSize(models[i]), o.ContextSize(models[j])
+				if contextI != contextJ {
+					return contextI > contextJ
+				}
+			}
 		}
 
 		// Otherwise, sort by friendly name
@@ -52,6 +142,37 @@ func SortModels(models []*ModelSummary) {
 		friendlyNameI := strings.ToLower(models[i].FriendlyName)
 		friendlyNameJ := strings.ToLower(models[j].FriendlyName)
 
-		return friendlyNameI < friendlyNameJ
+		if friendlyNameI != friendlyNameJ {
+			return friendlyNameI < friendlyNameJ
+		}
+
+		return compareVersions(models[i].Version, models[j].Version) > 0
 	})
 }
+
+// rank returns the position of the model among the pinned and then featured models, or the number of those models
+// if it is neither.
+func (o ModelOrder) rank(model *ModelSummary) int {
+	for i, name := range o.Pinned {
+		if model.HasName(name) {
+			return i
+		}
+	}
+	for i, name := range o.Featured {
+		if model.HasName(name) {
+			return len(o.Pinned) + i
+		}
+	}
+	return len(o.Pinned) + len(o.Featured)
+}
+
+// compareVersions compares two model versions, numerically if they are both numbers, such as "2" and "10", and as
+// strings otherwise, which orders dates such as "2024-08-06".
+func compareVersions(a, b string) int {
+	numberA, errA := strconv.Atoi(a)
+	numberB, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return numberA - numberB
+	}
+	return strings.Compare(a, b)
+}
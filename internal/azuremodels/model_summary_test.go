@@ -28,6 +28,84 @@ func TestModelSummary(t *testing.T) {
 		require.False(t, model.HasName("foo"))
 	})
 
+	t.Run("SplitModelVersion", func(t *testing.T) {
+		name, version := SplitModelVersion("gpt-4o@2024-05-13")
+		require.Equal(t, "gpt-4o", name)
+		require.Equal(t, "2024-05-13", version)
+
+		name, version = SplitModelVersion("gpt-4o")
+		require.Equal(t, "gpt-4o", name)
+		require.Empty(t, version)
+	})
+
+	t.Run("CheckVersion", func(t *testing.T) {
+		model := &ModelSummary{Name: "gpt-4o", Version: "2024-08-06"}
+
+		require.NoError(t, model.CheckVersion(""))
+		require.NoError(t, model.CheckVersion("2024-08-06"))
+		require.EqualError(t, model.CheckVersion("2024-05-13"), "gpt-4o is pinned to version 2024-05-13, but version 2024-08-06 is the one that runs now. Update the pin to run it, or run 'gh models list --all-versions' to see the versions of each model")
+	})
+
+	t.Run("labels", func(t *testing.T) {
+		model := &ModelSummary{Name: "gpt-4o", Version: "2", Labels: []string{"latest", "deprecated"}}
+
+		require.True(t, model.IsLatest())
+		require.True(t, model.IsDeprecated())
+		require.Equal(t, "gpt-4o@2", model.VersionedName())
+		require.False(t, (&ModelSummary{}).IsLatest())
+	})
+
+	t.Run("SortModels sorts versions of the same model newest first", func(t *testing.T) {
+		models := []*ModelSummary{
+			{FriendlyName: "Phi", Version: "2"},
+			{FriendlyName: "Phi", Version: "10"},
+			{FriendlyName: "GPT", Version: "2024-05-13"},
+			{FriendlyName: "GPT", Version: "2024-08-06"},
+		}
+
+		SortModels(models)
+
+		versions := []string{}
+		for _, model := range models {
+			versions = append(versions, model.Version)
+		}
+		require.Equal(t, []string{"2024-08-06", "2024-05-13", "10", "2"}, versions)
+	})
+
+	t.Run("ModelOrder sorts pinned and featured models first", func(t *testing.T) {
+		models := []*ModelSummary{
+			{Name: "a", FriendlyName: "A", Publisher: "Zeta", Popularity: 1},
+			{Name: "b", FriendlyName: "B", Publisher: "Alpha", Popularity: 5},
+			{Name: "c", FriendlyName: "C", Publisher: "Mu", Popularity: 3},
+			{Name: "d", FriendlyName: "D", Publisher: "Mu", Popularity: 4},
+		}
+		names := func() []string {
+			result := []string{}
+			for _, model := range models {
+				result = append(result, model.Name)
+			}
+			return result
+		}
+
+		ModelOrder{By: SortByPopularity}.Sort(models)
+		require.Equal(t, []string{"b", "d", "c", "a"}, names())
+
+		ModelOrder{By: SortByPublisher}.Sort(models)
+		require.Equal(t, []string{"b", "c", "d", "a"}, names())
+
+		contextSizes := map[string]int{"a": 8000, "b": 128000, "c": 32000}
+		ModelOrder{By: SortByContext, ContextSize: func(m *ModelSummary) int { return contextSizes[m.Name] }}.Sort(models)
+		require.Equal(t, []string{"b", "c", "a", "d"}, names())
+
+		ModelOrder{Pinned: []string{"C"}, Featured: []string{"d", "a"}}.Sort(models)
+		require.Equal(t, []string{"c", "d", "a", "b"}, names())
+	})
+
+	t.Run("ValidateSortOrder", func(t *testing.T) {
+		require.NoError(t, ValidateSortOrder(SortByPopularity))
+		require.EqualError(t, ValidateSortOrder("size"), "invalid sort order 'size'. Use one of: name, popularity, publisher, context")
+	})
+
 	t.Run("SortModels sorts given slice in-place by friendly name, case-insensitive", func(t *testing.T) {
 		modelA := &ModelSummary{Name: "z", FriendlyName: "AARDVARK"}
 		modelB := &ModelSummary{Name: "y", FriendlyName: "betta"}
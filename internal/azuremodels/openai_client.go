@@ -0,0 +1,158 @@
+package azuremodels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/github/gh-models/internal/sse"
+)
+
+// OpenAIClientConfig represents configurable settings for an OpenAI-compatible provider.
+type OpenAIClientConfig struct {
+	// Name is the name of the provider, used as the registry name of its models.
+	Name string
+	// BaseURL is the URL that the API paths, such as /chat/completions, are relative to.
+	BaseURL string
+	// APIKey is the key to authenticate with, or empty if the provider doesn't need one.
+	APIKey string
+	// AuthHeader is the header the API key is sent in. Keys sent in the Authorization header are sent as bearer tokens.
+	AuthHeader string
+}
+
+// OpenAIClient provides a client for any provider with an OpenAI-compatible API, such as a local llama.cpp or Ollama
+// server or an Azure OpenAI deployment.
+type OpenAIClient struct {
+	client *http.Client
+	cfg    *OpenAIClientConfig
+}
+
+// NewOpenAIClient returns a new client for an OpenAI-compatible provider using the given HTTP client and configuration.
+func NewOpenAIClient(httpClient *http.Client, cfg *OpenAIClientConfig) *OpenAIClient {
+	return &OpenAIClient{client: httpClient, cfg: cfg}
+}
+
+type openAIModelsResponse struct {
+	Data []struct {
+		ID      string `json:"id"`
+		OwnedBy string `json:"owned_by"`
+	} `json:"data"`
+}
+
+// GetChatCompletionStream returns a stream of chat completions using the given options.
+func (c *OpenAIClient) GetChatCompletionStream(ctx context.Context, req ChatCompletionOptions) (*ChatCompletionResponse, error) {
+	GetModelCapabilities(req.Model).AdaptRequest(&req)
+
+	bodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := c.newRequest(ctx, http.MethodPost, "/chat/completions", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, handleHTTPError(resp)
+	}
+
+	var chatCompletionResponse ChatCompletionResponse
+
+	if req.Stream {
+		chatCompletionResponse.Reader = sse.NewEventReader[ChatCompletion](resp.Body)
+	} else {
+		defer resp.Body.Close()
+		var completion ChatCompletion
+		if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+			return nil, err
+		}
+		chatCompletionResponse.Reader = sse.NewMockEventReader([]ChatCompletion{completion})
+	}
+
+	return &chatCompletionResponse, nil
+}
+
+// GetModelDetails returns the details of the specified model. OpenAI-compatible APIs don't describe their models, so
+// the details only say where the model is served from.
+func (c *OpenAIClient) GetModelDetails(ctx context.Context, registry, modelName, version string) (*ModelDetails, error) {
+	return &ModelDetails{
+		Description: fmt.Sprintf("%s is served by the %s provider at %s.", modelName, c.cfg.Name, c.cfg.BaseURL),
+	}, nil
+}
+
+// ListModels returns the models the provider serves. They are all assumed to be chat models.
+func (c *OpenAIClient) ListModels(ctx context.Context) ([]*ModelSummary, error) {
+	httpReq, err := c.newRequest(ctx, http.MethodGet, "/models", http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, handleHTTPError(resp)
+	}
+
+	var modelsResponse openAIModelsResponse
+	err = json.NewDecoder(resp.Body).Decode(&modelsResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*ModelSummary, 0, len(modelsResponse.Data))
+	for _, model := range modelsResponse.Data {
+		models = append(models, &ModelSummary{
+			ID:           model.ID,
+			Name:         model.ID,
+			FriendlyName: model.ID,
+			Task:         "chat-completion",
+			Publisher:    model.OwnedBy,
+			RegistryName: c.cfg.Name,
+			Labels:       []string{"latest"},
+		})
+	}
+
+	return models, nil
+}
+
+func (c *OpenAIClient) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	url := strings.TrimSuffix(c.cfg.BaseURL, "/") + path
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	if c.cfg.APIKey != "" {
+		if c.cfg.AuthHeader == "" || strings.EqualFold(c.cfg.AuthHeader, "Authorization") {
+			httpReq.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+		} else {
+			httpReq.Header.Set(c.cfg.AuthHeader, c.cfg.APIKey)
+		}
+	}
+
+	return httpReq, nil
+}
+
+// ListModelVersions returns the models the provider serves. OpenAI-compatible APIs don't version their models, so
+// these are the same as the models returned by ListModels.
+func (c *OpenAIClient) ListModelVersions(ctx context.Context) ([]*ModelSummary, error) {
+	return c.ListModels(ctx)
+}
@@ -0,0 +1,93 @@
+package azuremodels
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/github/gh-models/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ListModels", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+			require.Equal(t, "/v1/models", r.URL.Path)
+			require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			_, err := w.Write([]byte(`{"data": [{"id": "llama3", "owned_by": "meta"}]}`))
+			require.NoError(t, err)
+		}))
+		defer testServer.Close()
+
+		client := NewOpenAIClient(testServer.Client(), &OpenAIClientConfig{
+			Name:    "ollama",
+			BaseURL: testServer.URL + "/v1/",
+			APIKey:  "secret",
+		})
+
+		models, err := client.ListModels(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, models, 1)
+		require.Equal(t, "llama3", models[0].Name)
+		require.Equal(t, "meta", models[0].Publisher)
+		require.Equal(t, "ollama", models[0].RegistryName)
+		require.True(t, models[0].IsChatModel())
+	})
+
+	t.Run("GetChatCompletionStream", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "/chat/completions", r.URL.Path)
+			require.Equal(t, "secret", r.Header.Get("api-key"))
+			require.Empty(t, r.Header.Get("Authorization"))
+
+			var req ChatCompletionOptions
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Equal(t, "llama3", req.Model)
+			require.True(t, req.Stream)
+
+			data, err := json.Marshal(&ChatCompletion{Choices: []ChatChoice{{
+				FinishReason: "stop",
+				Delta:        &chatChoiceDelta{Content: util.Ptr("Hello")},
+			}}})
+			require.NoError(t, err)
+			_, err = w.Write([]byte("data: " + string(data) + "\n\ndata: [DONE]\n"))
+			require.NoError(t, err)
+		}))
+		defer testServer.Close()
+
+		client := NewOpenAIClient(testServer.Client(), &OpenAIClientConfig{
+			Name:       "azure",
+			BaseURL:    testServer.URL,
+			APIKey:     "secret",
+			AuthHeader: "api-key",
+		})
+
+		resp, err := client.GetChatCompletionStream(ctx, ChatCompletionOptions{Model: "llama3"})
+
+		require.NoError(t, err)
+		completion, err := resp.Reader.Read()
+		require.NoError(t, err)
+		require.Equal(t, "Hello", *completion.Choices[0].Delta.Content)
+	})
+
+	t.Run("returns errors from the provider", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Empty(t, r.Header.Get("Authorization"))
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer testServer.Close()
+
+		client := NewOpenAIClient(testServer.Client(), &OpenAIClientConfig{Name: "local", BaseURL: testServer.URL})
+
+		_, err := client.ListModels(ctx)
+
+		require.EqualError(t, err, "unauthorized")
+	})
+}
@@ -0,0 +1,154 @@
+package azuremodels
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ProviderSeparator separates the provider from the model in the names of models from additional providers, such as
+// "ollama:llama3".
+const ProviderSeparator = ":"
+
+// ProviderClient combines a default client with clients for additional providers. Models from the default client keep
+// their names, while models from the other providers are named "<provider>:<model>". Requests are routed to the
+// provider named in the model name.
+//
+// A provider whose models can't be listed, such as a local server that isn't running, is skipped when listing models,
+// so that it doesn't keep the models of the other providers from being used.
+type ProviderClient struct {
+	defaultClient Client
+	providers     map[string]Client
+	onUnavailable func(provider string, err error)
+
+	mu          sync.Mutex
+	unavailable map[string]error
+}
+
+// NewProviderClient returns a new client that routes requests between the default client and the given providers,
+// keyed by provider name. onUnavailable, if not nil, is called once for each provider whose models can't be listed.
+func NewProviderClient(defaultClient Client, providers map[string]Client, onUnavailable func(provider string, err error)) *ProviderClient {
+	return &ProviderClient{
+		defaultClient: defaultClient,
+		providers:     providers,
+		onUnavailable: onUnavailable,
+		unavailable:   map[string]error{},
+	}
+}
+
+// UnavailableProviderError is returned for a model of a provider whose models couldn't be listed.
+type UnavailableProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e *UnavailableProviderError) Error() string {
+	return fmt.Sprintf("failed to list the models of the %s provider: %v", e.Provider, e.Err)
+}
+
+func (e *UnavailableProviderError) Unwrap() error {
+	return e.Err
+}
+
+// CheckProvider returns an *UnavailableProviderError if the given model belongs to a provider of client whose models
+// couldn't be listed, so that a model the user asked for by name fails with the reason it's missing from the list.
+func CheckProvider(client Client, modelName string) error {
+	providerClient, ok := client.(*ProviderClient)
+	if !ok {
+		return nil
+	}
+	provider, _, ok := strings.Cut(modelName, ProviderSeparator)
+	if !ok {
+		return nil
+	}
+	providerClient.mu.Lock()
+	defer providerClient.mu.Unlock()
+	if err, ok := providerClient.unavailable[provider]; ok {
+		return &UnavailableProviderError{Provider: provider, Err: err}
+	}
+	return nil
+}
+
+// QualifyModelName returns the name of the given provider's model, for providers other than the default one.
+func QualifyModelName(provider, modelName string) string {
+	if provider == "" {
+		return modelName
+	}
+	return provider + ProviderSeparator + modelName
+}
+
+// route returns the client for the given model, and the name of the model in that client. Only a prefix that names a
+// provider is split off, since model names such as "llama3:8b" can contain the separator themselves.
+func (c *ProviderClient) route(modelName string) (Client, string) {
+	if provider, name, ok := strings.Cut(modelName, ProviderSeparator); ok {
+		if client, ok := c.providers[provider]; ok {
+			return client, name
+		}
+	}
+	return c.defaultClient, modelName
+}
+
+// GetChatCompletionStream returns a stream of chat completions from the provider of the requested model.
+func (c *ProviderClient) GetChatCompletionStream(ctx context.Context, req ChatCompletionOptions) (*ChatCompletionResponse, error) {
+	client, modelName := c.route(req.Model)
+	req.Model = modelName
+	return client.GetChatCompletionStream(ctx, req)
+}
+
+// GetModelDetails returns the details of the specified model from its provider.
+func (c *ProviderClient) GetModelDetails(ctx context.Context, registry, modelName, version string) (*ModelDetails, error) {
+	client, modelName := c.route(modelName)
+	return client.GetModelDetails(ctx, registry, modelName, version)
+}
+
+// ListModels returns the models of all the providers.
+func (c *ProviderClient) ListModels(ctx context.Context) ([]*ModelSummary, error) {
+	return c.listModels(ctx, Client.ListModels)
+}
+
+// ListModelVersions returns every version of the models of all the providers.
+func (c *ProviderClient) ListModelVersions(ctx context.Context) ([]*ModelSummary, error) {
+	return c.listModels(ctx, Client.ListModelVersions)
+}
+
+func (c *ProviderClient) listModels(ctx context.Context, list func(Client, context.Context) ([]*ModelSummary, error)) ([]*ModelSummary, error) {
+	models, err := list(c.defaultClient, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(c.providers))
+	for name := range c.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		providerModels, err := list(c.providers[name], ctx)
+		if err != nil {
+			c.skipProvider(name, err)
+			continue
+		}
+		for _, model := range providerModels {
+			qualified := *model
+			qualified.Name = QualifyModelName(name, model.Name)
+			models = append(models, &qualified)
+		}
+	}
+
+	return models, nil
+}
+
+// skipProvider records that the models of the given provider couldn't be listed, reporting it the first time.
+func (c *ProviderClient) skipProvider(provider string, err error) {
+	c.mu.Lock()
+	_, reported := c.unavailable[provider]
+	c.unavailable[provider] = err
+	c.mu.Unlock()
+
+	if !reported && c.onUnavailable != nil {
+		c.onUnavailable(provider, err)
+	}
+}
@@ -0,0 +1,95 @@
+package azuremodels
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderClient(t *testing.T) {
+	ctx := context.Background()
+
+	newClient := func(provider string, models ...string) *MockClient {
+		client := NewMockClient()
+		client.MockListModels = func(context.Context) ([]*ModelSummary, error) {
+			summaries := make([]*ModelSummary, len(models))
+			for i, model := range models {
+				summaries[i] = &ModelSummary{Name: model, FriendlyName: model, RegistryName: provider}
+			}
+			return summaries, nil
+		}
+		client.MockGetChatCompletionStream = func(_ context.Context, req ChatCompletionOptions) (*ChatCompletionResponse, error) {
+			return nil, errors.New(provider + " " + req.Model)
+		}
+		return client
+	}
+
+	client := NewProviderClient(newClient("azureml", "gpt-4o"), map[string]Client{
+		"ollama": newClient("ollama", "llama3"),
+		"azure":  newClient("azure", "gpt-4o"),
+	}, nil)
+
+	t.Run("ListModels qualifies the names of provider models", func(t *testing.T) {
+		models, err := client.ListModels(ctx)
+
+		require.NoError(t, err)
+		names := []string{}
+		for _, model := range models {
+			names = append(names, model.Name)
+		}
+		require.Equal(t, []string{"gpt-4o", "azure:gpt-4o", "ollama:llama3"}, names)
+		require.Equal(t, "llama3", models[2].FriendlyName)
+	})
+
+	t.Run("routes requests by provider", func(t *testing.T) {
+		_, err := client.GetChatCompletionStream(ctx, ChatCompletionOptions{Model: "ollama:llama3"})
+		require.EqualError(t, err, "ollama llama3")
+
+		_, err = client.GetChatCompletionStream(ctx, ChatCompletionOptions{Model: "gpt-4o"})
+		require.EqualError(t, err, "azureml gpt-4o")
+
+		_, err = client.GetChatCompletionStream(ctx, ChatCompletionOptions{Model: "unknown:model"})
+		require.EqualError(t, err, "azureml unknown:model")
+	})
+
+	t.Run("keeps tags in model names", func(t *testing.T) {
+		_, err := client.GetChatCompletionStream(ctx, ChatCompletionOptions{Model: "ollama:llama3:8b"})
+		require.EqualError(t, err, "ollama llama3:8b")
+
+		_, err = client.GetChatCompletionStream(ctx, ChatCompletionOptions{Model: "llama3:8b"})
+		require.EqualError(t, err, "azureml llama3:8b")
+	})
+
+	t.Run("skips providers whose models can't be listed", func(t *testing.T) {
+		offline := NewMockClient()
+		offline.MockListModels = func(context.Context) ([]*ModelSummary, error) {
+			return nil, errors.New("connection refused")
+		}
+		unavailable := []string{}
+		client := NewProviderClient(newClient("azureml", "gpt-4o"), map[string]Client{
+			"ollama": offline,
+			"azure":  newClient("azure", "gpt-4o"),
+		}, func(provider string, err error) {
+			unavailable = append(unavailable, provider+": "+err.Error())
+		})
+
+		for i := 0; i < 2; i++ {
+			models, err := client.ListModels(ctx)
+			require.NoError(t, err)
+			require.Len(t, models, 2)
+		}
+
+		require.Equal(t, []string{"ollama: connection refused"}, unavailable)
+		require.EqualError(t, CheckProvider(client, "ollama:llama3"), "failed to list the models of the ollama provider: connection refused")
+		require.NoError(t, CheckProvider(client, "azure:gpt-4o"))
+		require.NoError(t, CheckProvider(client, "gpt-4o"))
+		require.NoError(t, CheckProvider(NewMockClient(), "ollama:llama3"))
+	})
+
+	t.Run("QualifyModelName", func(t *testing.T) {
+		require.Equal(t, "ollama:llama3", QualifyModelName("ollama", "llama3"))
+		require.Equal(t, "gpt-4o", QualifyModelName("", "gpt-4o"))
+	})
+}
@@ -26,12 +26,22 @@ type ChatMessage struct {
 
 // ChatCompletionOptions represents available options for a chat completion request.
 type ChatCompletionOptions struct {
-	MaxTokens   *int          `json:"max_tokens,omitempty"`
-	Messages    []ChatMessage `json:"messages"`
-	Model       string        `json:"model"`
-	Stream      bool          `json:"stream,omitempty"`
-	Temperature *float64      `json:"temperature,omitempty"`
-	TopP        *float64      `json:"top_p,omitempty"`
+	FrequencyPenalty    *float64       `json:"frequency_penalty,omitempty"`
+	LogitBias           map[string]int `json:"logit_bias,omitempty"`
+	Logprobs            *bool          `json:"logprobs,omitempty"`
+	MaxCompletionTokens *int           `json:"max_completion_tokens,omitempty"`
+	MaxTokens           *int           `json:"max_tokens,omitempty"`
+	Messages            []ChatMessage  `json:"messages"`
+	Model               string         `json:"model"`
+	N                   *int           `json:"n,omitempty"`
+	PresencePenalty     *float64       `json:"presence_penalty,omitempty"`
+	ReasoningEffort     *string        `json:"reasoning_effort,omitempty"`
+	Seed                *int64         `json:"seed,omitempty"`
+	Stop                []string       `json:"stop,omitempty"`
+	Stream              bool           `json:"stream,omitempty"`
+	Temperature         *float64       `json:"temperature,omitempty"`
+	TopLogprobs         *int           `json:"top_logprobs,omitempty"`
+	TopP                *float64       `json:"top_p,omitempty"`
 }
 
 // ChatChoiceMessage is a message from a choice in a chat conversation.
@@ -45,22 +55,61 @@ type chatChoiceDelta struct {
 	Role    *string `json:"role,omitempty"`
 }
 
+const (
+	// FinishReasonStop means the model finished its response naturally, or hit a stop sequence.
+	FinishReasonStop = "stop"
+	// FinishReasonLength means the response was cut off because it reached the token limit.
+	FinishReasonLength = "length"
+	// FinishReasonContentFilter means the response was stopped by the content filter.
+	FinishReasonContentFilter = "content_filter"
+)
+
+// TokenLogprob is the log probability of a token.
+type TokenLogprob struct {
+	Bytes   []int   `json:"bytes,omitempty"`
+	Logprob float64 `json:"logprob"`
+	Token   string  `json:"token"`
+}
+
+// ChatTokenLogprob is the log probability of a token in a choice, along with the most likely tokens in its place.
+type ChatTokenLogprob struct {
+	TokenLogprob
+	TopLogprobs []TokenLogprob `json:"top_logprobs,omitempty"`
+}
+
+// ChatChoiceLogprobs holds the log probabilities of the tokens in a choice. When streaming, each chunk holds the log
+// probabilities of the tokens in its delta.
+type ChatChoiceLogprobs struct {
+	Content []ChatTokenLogprob `json:"content"`
+}
+
 // ChatChoice represents a choice in a chat completion.
 type ChatChoice struct {
-	Delta        *chatChoiceDelta   `json:"delta,omitempty"`
-	FinishReason string             `json:"finish_reason"`
-	Index        int32              `json:"index"`
-	Message      *ChatChoiceMessage `json:"message,omitempty"`
+	Delta        *chatChoiceDelta    `json:"delta,omitempty"`
+	FinishReason string              `json:"finish_reason"`
+	Index        int32               `json:"index"`
+	Logprobs     *ChatChoiceLogprobs `json:"logprobs,omitempty"`
+	Message      *ChatChoiceMessage  `json:"message,omitempty"`
+}
+
+// ChatCompletionUsage reports how many tokens a chat completion request used.
+type ChatCompletionUsage struct {
+	CompletionTokens int `json:"completion_tokens"`
+	PromptTokens     int `json:"prompt_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // ChatCompletion represents a chat completion.
 type ChatCompletion struct {
-	Choices []ChatChoice `json:"choices"`
+	Choices []ChatChoice         `json:"choices"`
+	Usage   *ChatCompletionUsage `json:"usage,omitempty"`
 }
 
 // ChatCompletionResponse represents a response to a chat completion request.
 type ChatCompletionResponse struct {
 	Reader sse.Reader[ChatCompletion]
+	// Model is the model that answered, when it may not be the requested model, as with FallbackClient.
+	Model string
 }
 
 type modelCatalogSearchResponse struct {
@@ -77,6 +126,7 @@ type modelCatalogSearchSummary struct {
 	RegistryName   string      `json:"registryName"`
 	Version        string      `json:"version"`
 	Summary        string      `json:"summary"`
+	Labels         []string    `json:"labels"`
 }
 
 type modelCatalogTextLimits struct {
@@ -2,29 +2,45 @@ package azuremodels
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"net/http"
 )
 
-// UnauthenticatedClient is for use by anonymous viewers to talk to the models API.
+// UnauthenticatedClient is for use by anonymous viewers to talk to the models API. The model catalog is public, so
+// anonymous viewers can list and view models, but running them requires authentication.
 type UnauthenticatedClient struct {
+	host    string
+	catalog *AzureClient
 }
 
-// NewUnauthenticatedClient contructs a new models API client for an anonymous viewer.
-func NewUnauthenticatedClient() *UnauthenticatedClient {
-	return &UnauthenticatedClient{}
+// NewUnauthenticatedClient contructs a new models API client for an anonymous viewer of the given GitHub host, using
+// the given API URLs for the catalog.
+func NewUnauthenticatedClient(host string, cfg *AzureClientConfig) *UnauthenticatedClient {
+	return NewUnauthenticatedClientWithHTTPClient(http.DefaultClient, host, cfg)
 }
 
-// GetChatCompletionStream returns an error because this functionality requires authentication.
+// NewUnauthenticatedClientWithHTTPClient contructs a new models API client for an anonymous viewer, using the given
+// HTTP client, which must not add credentials to requests.
+func NewUnauthenticatedClientWithHTTPClient(httpClient *http.Client, host string, cfg *AzureClientConfig) *UnauthenticatedClient {
+	return &UnauthenticatedClient{host: host, catalog: NewAzureClient(httpClient, "", cfg)}
+}
+
+// GetChatCompletionStream returns an error explaining how to authenticate, because running models requires it.
 func (c *UnauthenticatedClient) GetChatCompletionStream(ctx context.Context, opt ChatCompletionOptions) (*ChatCompletionResponse, error) {
-	return nil, errors.New("not authenticated")
+	return nil, fmt.Errorf("not authenticated. Running models requires a GitHub token: run 'gh auth login --hostname %s', or set GH_TOKEN to a personal access token with the 'models:read' permission", c.host)
 }
 
-// GetModelDetails returns an error because this functionality requires authentication.
+// GetModelDetails returns the details of the specified model in a particular registry.
 func (c *UnauthenticatedClient) GetModelDetails(ctx context.Context, registry, modelName, version string) (*ModelDetails, error) {
-	return nil, errors.New("not authenticated")
+	return c.catalog.GetModelDetails(ctx, registry, modelName, version)
 }
 
-// ListModels returns an error because this functionality requires authentication.
+// ListModels returns a list of available models.
 func (c *UnauthenticatedClient) ListModels(ctx context.Context) ([]*ModelSummary, error) {
-	return nil, errors.New("not authenticated")
+	return c.catalog.ListModels(ctx)
+}
+
+// ListModelVersions returns a list of every version of the available models.
+func (c *UnauthenticatedClient) ListModelVersions(ctx context.Context) ([]*ModelSummary, error) {
+	return c.catalog.ListModelVersions(ctx)
 }
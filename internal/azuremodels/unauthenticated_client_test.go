@@ -0,0 +1,40 @@
+package azuremodels
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnauthenticatedClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("lists models without a token", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Empty(t, r.Header.Get("Authorization"))
+			_, err := w.Write([]byte(`{"summaries": [{"name": "gpt-4o", "displayName": "OpenAI GPT-4o"}]}`))
+			require.NoError(t, err)
+		}))
+		defer testServer.Close()
+
+		cfg := &AzureClientConfig{ModelsURL: testServer.URL}
+		client := NewUnauthenticatedClientWithHTTPClient(testServer.Client(), "github.com", cfg)
+
+		models, err := client.ListModels(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, models, 1)
+		require.Equal(t, "gpt-4o", models[0].Name)
+	})
+
+	t.Run("explains how to authenticate to run models", func(t *testing.T) {
+		client := NewUnauthenticatedClient("octocorp.ghe.com", NewDefaultAzureClientConfig())
+
+		_, err := client.GetChatCompletionStream(ctx, ChatCompletionOptions{Model: "gpt-4o"})
+
+		require.EqualError(t, err, "not authenticated. Running models requires a GitHub token: run 'gh auth login --hostname octocorp.ghe.com', or set GH_TOKEN to a personal access token with the 'models:read' permission")
+	})
+}
@@ -0,0 +1,259 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/github/gh-models/internal/azuremodels"
+)
+
+// languageNeedPrefix starts a need for a language, such as "language:fr".
+const languageNeedPrefix = "language:"
+
+// need is a capability that Select can require of a model.
+type need struct {
+	// description completes "models that ...", such as "accept images".
+	description string
+	met         func(details *azuremodels.ModelDetails) bool
+}
+
+// needs lists the capabilities that can be required, by name. Models don't declare most capabilities, so they are
+// inferred from the model's input types, tags and description.
+var needs = map[string]need{
+	"vision": {"accept images", func(d *azuremodels.ModelDetails) bool {
+		return equalFoldAny(d.SupportedInputModalities, "image")
+	}},
+	"audio": {"accept audio", func(d *azuremodels.ModelDetails) bool {
+		return equalFoldAny(d.SupportedInputModalities, "audio")
+	}},
+	"json": {"support JSON output", func(d *azuremodels.ModelDetails) bool {
+		return containsFold(d.Tags, "json") || containsFold([]string{d.Description}, "json")
+	}},
+	"tools": {"support tool calling", func(d *azuremodels.ModelDetails) bool {
+		return containsFold(d.Tags, "tool") || containsFold(d.Tags, "function") ||
+			containsFold([]string{d.Description}, "tool calling") || containsFold([]string{d.Description}, "function calling")
+	}},
+	"reasoning": {"reason", func(d *azuremodels.ModelDetails) bool {
+		return equalFoldAny(d.Tags, "reasoning")
+	}},
+}
+
+// NeedNames lists the names of the capabilities that can be required, without the language need.
+func NeedNames() []string {
+	names := make([]string, 0, len(needs))
+	for name := range needs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Requirements describes the model that Select chooses.
+type Requirements struct {
+	// Needs lists the capabilities the model must have: names from NeedNames, or "language:<language>" for a
+	// language it must support.
+	Needs []string
+	// MinContext is the number of input tokens the model must accept, if it isn't 0.
+	MinContext int
+	// Preferred lists the names of the models to choose first, in order, if they meet the requirements.
+	Preferred []string
+	// Available limits the choice to the models with these names, if it isn't nil, such as the models that can run now
+	// rather than those in a cached catalog.
+	Available []string
+}
+
+// Validate returns an error if a need isn't known.
+func (r Requirements) Validate() error {
+	for _, name := range r.Needs {
+		if _, ok := needs[name]; ok {
+			continue
+		}
+		if language, ok := strings.CutPrefix(name, languageNeedPrefix); ok {
+			if _, known := languageName(language); !known {
+				return fmt.Errorf("unknown language '%s'. Use a language code, such as %sfr, or a name, such as %sFrench", language, languageNeedPrefix, languageNeedPrefix)
+			}
+			continue
+		}
+		return fmt.Errorf("unknown need '%s'. Use one of: %s, or %s<language>", name, strings.Join(NeedNames(), ", "), languageNeedPrefix)
+	}
+	if r.MinContext < 0 {
+		return errors.New("the minimum context can't be negative")
+	}
+	return nil
+}
+
+// Describe completes "models that ..." with the requirements, such as "accept images and have at least 64000 input
+// tokens".
+func (r Requirements) Describe() string {
+	parts := []string{}
+	for _, name := range r.Needs {
+		if language, ok := strings.CutPrefix(name, languageNeedPrefix); ok {
+			if known, ok := languageName(language); ok {
+				language = known
+			}
+			parts = append(parts, "support "+language)
+		} else {
+			parts = append(parts, needs[name].description)
+		}
+	}
+	if r.MinContext > 0 {
+		parts = append(parts, fmt.Sprintf("have at least %d input tokens", r.MinContext))
+	}
+	switch len(parts) {
+	case 0:
+		return "chat"
+	case 1:
+		return parts[0]
+	default:
+		return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+	}
+}
+
+func (r Requirements) metBy(model *Model) bool {
+	if !model.Summary.IsChatModel() {
+		return false
+	}
+	if r.Available != nil && !equalFoldAny(r.Available, model.Summary.Name) {
+		return false
+	}
+	if len(r.Needs) == 0 && r.MinContext == 0 {
+		return true
+	}
+	if model.Details == nil {
+		return false
+	}
+	for _, name := range r.Needs {
+		if language, ok := strings.CutPrefix(name, languageNeedPrefix); ok {
+			if !supportsLanguage(model.Details.SupportedLanguages, language) {
+				return false
+			}
+		} else if !needs[name].met(model.Details) {
+			return false
+		}
+	}
+	return model.Details.MaxInputTokens >= r.MinContext
+}
+
+// preference returns the position of the model among the preferred models, or the number of preferred models if it
+// isn't one of them.
+func (r Requirements) preference(model *Model) int {
+	for i, name := range r.Preferred {
+		if model.Summary.HasName(name) {
+			return i
+		}
+	}
+	return len(r.Preferred)
+}
+
+// Selection is the model chosen by Select, and why.
+type Selection struct {
+	Model *Model
+	// Candidates is the number of models that met the requirements.
+	Candidates int
+	// Reason explains why the model was chosen over the other candidates.
+	Reason string
+}
+
+// rateLimitTierRanks ranks the rate limit tiers, as a sign of how capable a model is: models in the high tier are
+// the larger models.
+var rateLimitTierRanks = map[string]int{"high": 0, "low": 1}
+
+func rateLimitTierRank(details *azuremodels.ModelDetails) int {
+	if details != nil {
+		if rank, ok := rateLimitTierRanks[strings.ToLower(details.RateLimitTier)]; ok {
+			return rank
+		}
+	}
+	return len(rateLimitTierRanks)
+}
+
+// Select chooses the best model that meets the requirements: the first preferred model that meets them, or else the
+// model in the highest rate limit tier, then the most popular, and then the one with the largest context.
+func Select(models []*Model, requirements Requirements) (*Selection, error) {
+	if err := requirements.Validate(); err != nil {
+		return nil, err
+	}
+
+	candidates := []*Model{}
+	for _, model := range models {
+		if requirements.metBy(model) {
+			candidates = append(candidates, model)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no available models %s", requirements.Describe())
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		before, _ := requirements.compare(candidates[i], candidates[j])
+		return before
+	})
+
+	selected := candidates[0]
+	tier := ""
+	if selected.Details != nil && selected.Details.RateLimitTier != "" {
+		tier = fmt.Sprintf(" in the %s rate limit tier", strings.ToLower(selected.Details.RateLimitTier))
+	}
+
+	var reason string
+	if len(candidates) == 1 {
+		reason = "it is the only model that meets the requirements"
+		if requirements.preference(selected) < len(requirements.Preferred) {
+			reason = "it is the first of your preferred models that meets the requirements"
+		}
+		return &Selection{Model: selected, Candidates: 1, Reason: reason}, nil
+	}
+
+	// The reason is the criterion that ranked the selected model above the next one.
+	switch _, decidedBy := requirements.compare(selected, candidates[1]); decidedBy {
+	case criterionPreference:
+		reason = "it is the first of your preferred models that meets the requirements"
+	case criterionTier:
+		reason = fmt.Sprintf("it is the only model%s that meets the requirements", tier)
+	case criterionPopularity:
+		reason = fmt.Sprintf("it is the most popular model%s that meets the requirements", tier)
+	case criterionContext:
+		reason = fmt.Sprintf("it has the largest context of the most popular models%s that meet the requirements", tier)
+	default:
+		reason = fmt.Sprintf("it is first by name of the equally ranked models%s that meet the requirements", tier)
+	}
+	return &Selection{Model: selected, Candidates: len(candidates), Reason: reason}, nil
+}
+
+// criterion is what Select ranks models by, in order.
+type criterion int
+
+const (
+	criterionPreference criterion = iota
+	criterionTier
+	criterionPopularity
+	criterionContext
+	criterionName
+)
+
+// compare returns whether model a ranks before model b, and the criterion that decided it: preference, then rate
+// limit tier, popularity, context size, and finally name.
+func (r Requirements) compare(a, b *Model) (bool, criterion) {
+	if prefA, prefB := r.preference(a), r.preference(b); prefA != prefB {
+		return prefA < prefB, criterionPreference
+	}
+	if tierA, tierB := rateLimitTierRank(a.Details), rateLimitTierRank(b.Details); tierA != tierB {
+		return tierA < tierB, criterionTier
+	}
+	if a.Summary.Popularity != b.Summary.Popularity {
+		return a.Summary.Popularity > b.Summary.Popularity, criterionPopularity
+	}
+	if contextA, contextB := maxInputTokens(a), maxInputTokens(b); contextA != contextB {
+		return contextA > contextB, criterionContext
+	}
+	return strings.ToLower(a.Summary.Name) < strings.ToLower(b.Summary.Name), criterionName
+}
+
+func maxInputTokens(model *Model) int {
+	if model.Details == nil {
+		return 0
+	}
+	return model.Details.MaxInputTokens
+}
@@ -0,0 +1,124 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelect(t *testing.T) {
+	models := []*Model{
+		{
+			Summary: &azuremodels.ModelSummary{Name: "gpt-4o", Task: "chat-completion", Popularity: 9},
+			Details: &azuremodels.ModelDetails{
+				SupportedInputModalities: []string{"text", "image"},
+				SupportedLanguages:       []string{"en", "fr"},
+				Description:              "Supports JSON mode and tool calling.",
+				MaxInputTokens:           131072,
+				RateLimitTier:            "high",
+			},
+		},
+		{
+			Summary: &azuremodels.ModelSummary{Name: "gpt-4o-mini", Task: "chat-completion", Popularity: 10},
+			Details: &azuremodels.ModelDetails{
+				SupportedInputModalities: []string{"text", "image"},
+				SupportedLanguages:       []string{"English", "German"},
+				Description:              "Supports JSON mode.",
+				MaxInputTokens:           131072,
+				RateLimitTier:            "low",
+			},
+		},
+		{
+			Summary: &azuremodels.ModelSummary{Name: "phi-4", Task: "chat-completion", Popularity: 5},
+			Details: &azuremodels.ModelDetails{
+				SupportedInputModalities: []string{"text"},
+				Tags:                     []string{"reasoning"},
+				MaxInputTokens:           16384,
+				RateLimitTier:            "low",
+			},
+		},
+		{
+			Summary: &azuremodels.ModelSummary{Name: "text-embedding-3-small", Task: "embeddings", Popularity: 20},
+			Details: &azuremodels.ModelDetails{RateLimitTier: "embeddings"},
+		},
+	}
+
+	selectName := func(t *testing.T, requirements Requirements) string {
+		selection, err := Select(models, requirements)
+		require.NoError(t, err)
+		return selection.Model.Summary.Name
+	}
+
+	t.Run("prefers higher rate limit tiers, then popularity", func(t *testing.T) {
+		selection, err := Select(models, Requirements{})
+
+		require.NoError(t, err)
+		require.Equal(t, "gpt-4o", selection.Model.Summary.Name)
+		require.Equal(t, 3, selection.Candidates)
+		require.Equal(t, "it is the only model in the high rate limit tier that meets the requirements", selection.Reason)
+	})
+
+	t.Run("gives the criterion that decided the selection as the reason", func(t *testing.T) {
+		tied := func(name string, popularity float64, context int) *Model {
+			return &Model{
+				Summary: &azuremodels.ModelSummary{Name: name, Task: "chat-completion", Popularity: popularity},
+				Details: &azuremodels.ModelDetails{MaxInputTokens: context, RateLimitTier: "low"},
+			}
+		}
+
+		selection, err := Select([]*Model{tied("a", 5, 8000), tied("b", 7, 8000)}, Requirements{})
+		require.NoError(t, err)
+		require.Equal(t, "b", selection.Model.Summary.Name)
+		require.Equal(t, "it is the most popular model in the low rate limit tier that meets the requirements", selection.Reason)
+
+		selection, err = Select([]*Model{tied("a", 5, 8000), tied("b", 5, 128000)}, Requirements{})
+		require.NoError(t, err)
+		require.Equal(t, "b", selection.Model.Summary.Name)
+		require.Equal(t, "it has the largest context of the most popular models in the low rate limit tier that meet the requirements", selection.Reason)
+
+		selection, err = Select([]*Model{tied("b", 5, 8000), tied("a", 5, 8000)}, Requirements{})
+		require.NoError(t, err)
+		require.Equal(t, "a", selection.Model.Summary.Name)
+		require.Equal(t, "it is first by name of the equally ranked models in the low rate limit tier that meet the requirements", selection.Reason)
+	})
+
+	t.Run("selects models that meet the requirements", func(t *testing.T) {
+		require.Equal(t, "phi-4", selectName(t, Requirements{Needs: []string{"reasoning"}}))
+		require.Equal(t, "gpt-4o", selectName(t, Requirements{Needs: []string{"language:fr"}}))
+		require.Equal(t, "gpt-4o", selectName(t, Requirements{Needs: []string{"tools"}, MinContext: 64000}))
+		require.Equal(t, "gpt-4o-mini", selectName(t, Requirements{Needs: []string{"vision", "json"}, Available: []string{"gpt-4o-mini", "phi-4"}}))
+	})
+
+	t.Run("matches languages by code or by name", func(t *testing.T) {
+		require.Equal(t, "gpt-4o", selectName(t, Requirements{Needs: []string{"language:French"}}))
+		require.Equal(t, "gpt-4o-mini", selectName(t, Requirements{Needs: []string{"language:de"}}))
+		require.Equal(t, "gpt-4o-mini", selectName(t, Requirements{Needs: []string{"language:german"}}))
+
+		selection, err := Select(models, Requirements{Needs: []string{"language:en-US"}})
+		require.NoError(t, err)
+		require.Equal(t, 2, selection.Candidates)
+
+		_, err = Select(models, Requirements{Needs: []string{"language:de", "language:fr"}})
+		require.EqualError(t, err, "no available models support German and support French")
+
+		_, err = Select(models, Requirements{Needs: []string{"language:klingon"}})
+		require.EqualError(t, err, "unknown language 'klingon'. Use a language code, such as language:fr, or a name, such as language:French")
+	})
+
+	t.Run("selects preferred models first", func(t *testing.T) {
+		selection, err := Select(models, Requirements{Needs: []string{"json"}, Preferred: []string{"phi-4", "gpt-4o-mini"}})
+
+		require.NoError(t, err)
+		require.Equal(t, "gpt-4o-mini", selection.Model.Summary.Name)
+		require.Equal(t, "it is the first of your preferred models that meets the requirements", selection.Reason)
+	})
+
+	t.Run("reports requirements that no model meets", func(t *testing.T) {
+		_, err := Select(models, Requirements{Needs: []string{"audio", "json"}, MinContext: 64000})
+		require.EqualError(t, err, "no available models accept audio, support JSON output and have at least 64000 input tokens")
+
+		_, err = Select(models, Requirements{Needs: []string{"telepathy"}})
+		require.EqualError(t, err, "unknown need 'telepathy'. Use one of: audio, json, reasoning, tools, vision, or language:<language>")
+	})
+}
@@ -0,0 +1,191 @@
+// Package catalog provides the model catalog with the details of every model, cached on disk so that commands which
+// need the details of many models, such as search, don't fetch them on every run.
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/config"
+	"github.com/github/gh-models/internal/azuremodels"
+)
+
+const (
+	// maxAge is how long a cached catalog is used before it is fetched again.
+	maxAge = 24 * time.Hour
+	// detailsConcurrency is the number of model details fetched at once.
+	detailsConcurrency = 8
+	// formatVersion is increased when the details of models gain fields, or are parsed differently, so that older caches
+	// are fetched again.
+	formatVersion = 2
+)
+
+// Model is a model in the catalog, with its details if they could be fetched.
+type Model struct {
+	Summary *azuremodels.ModelSummary `json:"summary"`
+	Details *azuremodels.ModelDetails `json:"details,omitempty"`
+}
+
+// Catalog holds the available models and their details.
+type Catalog struct {
+	FormatVersion int       `json:"format_version"`
+	FetchedAt     time.Time `json:"fetched_at"`
+	Models        []*Model  `json:"models"`
+}
+
+// Loader loads the catalog from the cache, or from the API when the cache is missing or stale.
+type Loader struct {
+	client    azuremodels.Client
+	cachePath string
+	now       func() time.Time
+}
+
+// CachePath returns the path of the cached catalog for the given GitHub host in the gh cache directory.
+func CachePath(host string) string {
+	return filepath.Join(config.CacheDir(), "gh-models", "catalog-"+hostFileName(host)+".json")
+}
+
+// SnapshotPath returns the path of the last snapshot of the catalog for the given GitHub host in the gh state
+// directory.
+func SnapshotPath(host string) string {
+	return filepath.Join(config.StateDir(), "gh-models", "snapshot-"+hostFileName(host)+".json")
+}
+
+func hostFileName(host string) string {
+	if host == "" {
+		return "default"
+	}
+	return host
+}
+
+// NewLoader returns a new loader that fetches the catalog with the given client and caches it at the given path. If
+// the path is empty, the catalog isn't cached.
+func NewLoader(client azuremodels.Client, cachePath string) *Loader {
+	return &Loader{client: client, cachePath: cachePath, now: time.Now}
+}
+
+// Load returns the catalog. It is read from the cache if the cache is less than a day old, unless refresh is true.
+func (l *Loader) Load(ctx context.Context, refresh bool) (*Catalog, error) {
+	if !refresh {
+		catalog, err := l.readCache()
+		if err == nil && catalog.FormatVersion == formatVersion && l.now().Sub(catalog.FetchedAt) < maxAge {
+			return catalog, nil
+		}
+	}
+
+	catalog, err := l.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The catalog can be used even if it can't be cached, so a failure to write the cache isn't an error.
+	_ = l.writeCache(catalog)
+	return catalog, nil
+}
+
+// Cached returns the cached catalog, however old it is, without fetching anything. It returns an error if there is no
+// cached catalog, for uses such as previews that can do without the catalog rather than wait for it.
+func (l *Loader) Cached() (*Catalog, error) {
+	return l.readCache()
+}
+
+// fetch fetches the models, and then the details of each model concurrently. Models whose details can't be fetched
+// are kept without details.
+func (l *Loader) fetch(ctx context.Context) (*Catalog, error) {
+	summaries, err := l.client.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &Catalog{FormatVersion: formatVersion, FetchedAt: l.now(), Models: make([]*Model, len(summaries))}
+	semaphore := make(chan struct{}, detailsConcurrency)
+	var wg sync.WaitGroup
+	for i, summary := range summaries {
+		catalog.Models[i] = &Model{Summary: summary}
+
+		wg.Add(1)
+		go func(model *Model) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			details, err := l.client.GetModelDetails(ctx, model.Summary.RegistryName, model.Summary.Name, model.Summary.Version)
+			if err == nil {
+				model.Details = details
+			}
+		}(catalog.Models[i])
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+func (l *Loader) readCache() (*Catalog, error) {
+	if l.cachePath == "" {
+		return nil, errors.New("the catalog is not cached")
+	}
+	return ReadFile(l.cachePath)
+}
+
+func (l *Loader) writeCache(catalog *Catalog) error {
+	if l.cachePath == "" {
+		return nil
+	}
+	return catalog.WriteFile(l.cachePath)
+}
+
+// ReadFile reads a catalog from the given file, such as a snapshot written by WriteFile.
+func ReadFile(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &catalog, nil
+}
+
+// WriteFile writes the catalog to the given file as JSON, creating its directory if needed.
+func (c *Catalog) WriteFile(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Find returns the model with the given name or display name, or nil if there is none.
+func (c *Catalog) Find(name string) *Model {
+	for _, model := range c.Models {
+		if model.Summary.HasName(name) {
+			return model
+		}
+	}
+	return nil
+}
+
+// ChatModels returns the models for chat completions.
+func (c *Catalog) ChatModels() []*Model {
+	models := []*Model{}
+	for _, model := range c.Models {
+		if model.Summary.IsChatModel() {
+			models = append(models, model)
+		}
+	}
+	return models
+}
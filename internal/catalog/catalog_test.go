@@ -0,0 +1,88 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader(t *testing.T) {
+	ctx := context.Background()
+
+	newClient := func(listCalls *int) *azuremodels.MockClient {
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(context.Context) ([]*azuremodels.ModelSummary, error) {
+			*listCalls++
+			return []*azuremodels.ModelSummary{
+				{Name: "gpt-4o", FriendlyName: "OpenAI GPT-4o", Task: "chat-completion"},
+				{Name: "broken", FriendlyName: "Broken", Task: "chat-completion"},
+			}, nil
+		}
+		client.MockGetModelDetails = func(_ context.Context, _, modelName, _ string) (*azuremodels.ModelDetails, error) {
+			if modelName == "broken" {
+				return nil, errors.New("not found")
+			}
+			return &azuremodels.ModelDetails{Tags: []string{"multimodal"}}, nil
+		}
+		return client
+	}
+
+	t.Run("fetches the details of each model", func(t *testing.T) {
+		listCalls := 0
+		loader := NewLoader(newClient(&listCalls), "")
+
+		catalog, err := loader.Load(ctx, false)
+
+		require.NoError(t, err)
+		require.Len(t, catalog.Models, 2)
+		require.Equal(t, []string{"multimodal"}, catalog.Find("gpt-4o").Details.Tags)
+		require.Nil(t, catalog.Find("broken").Details)
+		require.Nil(t, catalog.Find("missing"))
+	})
+
+	t.Run("caches the catalog for a day", func(t *testing.T) {
+		listCalls := 0
+		loader := NewLoader(newClient(&listCalls), filepath.Join(t.TempDir(), "cache", "catalog.json"))
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		loader.now = func() time.Time { return now }
+
+		_, err := loader.Load(ctx, false)
+		require.NoError(t, err)
+		catalog, err := loader.Load(ctx, false)
+		require.NoError(t, err)
+		require.Equal(t, 1, listCalls)
+		require.Equal(t, []string{"multimodal"}, catalog.Find("gpt-4o").Details.Tags)
+
+		_, err = loader.Load(ctx, true)
+		require.NoError(t, err)
+		require.Equal(t, 2, listCalls)
+
+		now = now.Add(25 * time.Hour)
+		_, err = loader.Load(ctx, false)
+		require.NoError(t, err)
+		require.Equal(t, 3, listCalls)
+	})
+
+	t.Run("Cached reads only an existing cache", func(t *testing.T) {
+		listCalls := 0
+		loader := NewLoader(newClient(&listCalls), filepath.Join(t.TempDir(), "catalog.json"))
+
+		_, err := loader.Cached()
+		require.Error(t, err)
+		require.Zero(t, listCalls)
+
+		_, err = loader.Load(ctx, false)
+		require.NoError(t, err)
+		loader.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+
+		catalog, err := loader.Cached()
+		require.NoError(t, err)
+		require.Equal(t, []string{"multimodal"}, catalog.Find("gpt-4o").Details.Tags)
+		require.Equal(t, 1, listCalls)
+	})
+}
@@ -0,0 +1,113 @@
+package catalog
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/github/gh-models/internal/azuremodels"
+)
+
+// Kinds of change between two catalogs.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change is a difference between two catalogs: a model that was added or removed, or a change to one field of a
+// model.
+type Change struct {
+	Model string `json:"model"`
+	Kind  string `json:"change"`
+	Field string `json:"field,omitempty"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// diffField is a field of a model that is compared between catalogs.
+type diffField struct {
+	name string
+	// fromDetails is true for fields of the model's details, which are only compared when both catalogs have them.
+	fromDetails bool
+	value       func(summary *azuremodels.ModelSummary, details *azuremodels.ModelDetails) string
+}
+
+// diffFields lists the fields that Diff compares. Details are never nil for fields from the details.
+var diffFields = []diffField{
+	{"version", false, func(s *azuremodels.ModelSummary, _ *azuremodels.ModelDetails) string {
+		return s.Version
+	}},
+	{"max input tokens", true, func(_ *azuremodels.ModelSummary, d *azuremodels.ModelDetails) string {
+		return formatTokens(d.MaxInputTokens)
+	}},
+	{"max output tokens", true, func(_ *azuremodels.ModelSummary, d *azuremodels.ModelDetails) string {
+		return formatTokens(d.MaxOutputTokens)
+	}},
+	{"rate limit tier", true, func(_ *azuremodels.ModelSummary, d *azuremodels.ModelDetails) string {
+		return d.RateLimitTier
+	}},
+	{"license", true, func(_ *azuremodels.ModelSummary, d *azuremodels.ModelDetails) string {
+		return d.License
+	}},
+}
+
+// Diff returns the changes from one catalog to another, sorted by model name. Models are matched by name, so a new
+// version of a model is a change to its version rather than an added model. The details of a model are only compared
+// if both catalogs have them, since details that couldn't be fetched aren't a change.
+func Diff(from, to *Catalog) []Change {
+	oldModels := modelsByName(from)
+	newModels := modelsByName(to)
+
+	changes := []Change{}
+	for name, oldModel := range oldModels {
+		if _, ok := newModels[name]; !ok {
+			changes = append(changes, Change{Model: name, Kind: ChangeRemoved, Old: oldModel.Summary.Version})
+		}
+	}
+	for name, newModel := range newModels {
+		oldModel, ok := oldModels[name]
+		if !ok {
+			changes = append(changes, Change{Model: name, Kind: ChangeAdded, New: newModel.Summary.Version})
+			continue
+		}
+
+		for _, field := range diffFields {
+			if field.fromDetails && (oldModel.Details == nil || newModel.Details == nil) {
+				continue
+			}
+			oldValue := field.value(oldModel.Summary, oldModel.Details)
+			newValue := field.value(newModel.Summary, newModel.Details)
+			if oldValue != newValue {
+				changes = append(changes, Change{Model: name, Kind: ChangeChanged, Field: field.name, Old: oldValue, New: newValue})
+			}
+		}
+	}
+
+	fieldOrder := map[string]int{}
+	for i, field := range diffFields {
+		fieldOrder[field.name] = i
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Model != changes[j].Model {
+			return strings.ToLower(changes[i].Model) < strings.ToLower(changes[j].Model)
+		}
+		return fieldOrder[changes[i].Field] < fieldOrder[changes[j].Field]
+	})
+	return changes
+}
+
+func modelsByName(catalog *Catalog) map[string]*Model {
+	models := make(map[string]*Model, len(catalog.Models))
+	for _, model := range catalog.Models {
+		models[model.Summary.Name] = model
+	}
+	return models
+}
+
+func formatTokens(tokens int) string {
+	if tokens == 0 {
+		return ""
+	}
+	return strconv.Itoa(tokens)
+}
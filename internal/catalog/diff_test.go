@@ -0,0 +1,55 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	from := &Catalog{Models: []*Model{
+		{
+			Summary: &azuremodels.ModelSummary{Name: "gpt-4o", Version: "1"},
+			Details: &azuremodels.ModelDetails{MaxInputTokens: 128000, MaxOutputTokens: 4096, RateLimitTier: "high", License: "custom"},
+		},
+		{Summary: &azuremodels.ModelSummary{Name: "retired", Version: "3"}},
+		{Summary: &azuremodels.ModelSummary{Name: "unchanged", Version: "1"}},
+	}}
+	to := &Catalog{Models: []*Model{
+		{Summary: &azuremodels.ModelSummary{Name: "Added", Version: "1"}},
+		{
+			Summary: &azuremodels.ModelSummary{Name: "gpt-4o", Version: "2"},
+			Details: &azuremodels.ModelDetails{MaxInputTokens: 128000, MaxOutputTokens: 16384, RateLimitTier: "low", License: "custom"},
+		},
+		{Summary: &azuremodels.ModelSummary{Name: "unchanged", Version: "1"}},
+	}}
+
+	changes := Diff(from, to)
+
+	require.Equal(t, []Change{
+		{Model: "Added", Kind: ChangeAdded, New: "1"},
+		{Model: "gpt-4o", Kind: ChangeChanged, Field: "version", Old: "1", New: "2"},
+		{Model: "gpt-4o", Kind: ChangeChanged, Field: "max output tokens", Old: "4096", New: "16384"},
+		{Model: "gpt-4o", Kind: ChangeChanged, Field: "rate limit tier", Old: "high", New: "low"},
+		{Model: "retired", Kind: ChangeRemoved, Old: "3"},
+	}, changes)
+	require.Empty(t, Diff(to, to))
+}
+
+func TestDiffSkipsMissingDetails(t *testing.T) {
+	details := &azuremodels.ModelDetails{MaxInputTokens: 128000, RateLimitTier: "high", License: "custom"}
+	from := &Catalog{Models: []*Model{
+		{Summary: &azuremodels.ModelSummary{Name: "gpt-4o", Version: "1"}, Details: details},
+		{Summary: &azuremodels.ModelSummary{Name: "phi-4", Version: "1"}},
+	}}
+	// The details of gpt-4o couldn't be fetched for the second catalog, as when the API fails for one model.
+	to := &Catalog{Models: []*Model{
+		{Summary: &azuremodels.ModelSummary{Name: "gpt-4o", Version: "2"}},
+		{Summary: &azuremodels.ModelSummary{Name: "phi-4", Version: "1"}, Details: details},
+	}}
+
+	require.Equal(t, []Change{
+		{Model: "gpt-4o", Kind: ChangeChanged, Field: "version", Old: "1", New: "2"},
+	}, Diff(from, to))
+}
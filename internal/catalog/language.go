@@ -0,0 +1,56 @@
+package catalog
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// languageNames maps the lowercase English names of languages to their names, so that languages given by name can be
+// matched with those given by code. It's built on first use from the two-letter language codes.
+var languageNames = sync.OnceValue(func() map[string]string {
+	english := display.English.Languages()
+	names := map[string]string{}
+	for first := 'a'; first <= 'z'; first++ {
+		for second := 'a'; second <= 'z'; second++ {
+			tag, err := language.Parse(string([]rune{first, second}))
+			if err != nil {
+				continue
+			}
+			if name := english.Name(tag); name != "" {
+				names[strings.ToLower(name)] = name
+			}
+		}
+	}
+	return names
+})
+
+// languageName returns the English name of a language given as a code, such as "fr" or "zh-Hans", or as a name, such as
+// "French", or false if it isn't a known language. Regional variants are named after their language, so "pt-BR" is
+// "Portuguese".
+func languageName(value string) (string, bool) {
+	if tag, err := language.Parse(value); err == nil {
+		base, _ := tag.Base()
+		if name := display.English.Languages().Name(base); name != "" {
+			return name, true
+		}
+	}
+	name, ok := languageNames()[strings.ToLower(value)]
+	return name, ok
+}
+
+// supportsLanguage returns true if the given languages, which models list as codes or as names, include the language.
+func supportsLanguage(languages []string, lang string) bool {
+	want, ok := languageName(lang)
+	if !ok {
+		return equalFoldAny(languages, lang)
+	}
+	for _, l := range languages {
+		if name, ok := languageName(l); ok && name == want {
+			return true
+		}
+	}
+	return equalFoldAny(languages, lang)
+}
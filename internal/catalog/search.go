@@ -0,0 +1,176 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/github/gh-models/internal/azuremodels"
+)
+
+// stopWords are left out of search queries, since they match most models or say nothing about them.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "for": true, "in": true, "model": true, "models": true, "of": true,
+	"that": true, "the": true, "with": true,
+}
+
+// Query describes a search of the catalog: free text, ranked by where it matches, and filters that a model must pass.
+type Query struct {
+	// Text is the free text to search for. Each word is matched separately.
+	Text string
+	// Publisher only includes models whose publisher contains it.
+	Publisher string
+	// Tags only includes models with all of these tags.
+	Tags []string
+	// Language only includes models that support it, such as "Spanish".
+	Language string
+	// InputType only includes models that accept it, such as "image".
+	InputType string
+}
+
+// Result is a model that matched a search, with its score and the fields the query matched.
+type Result struct {
+	Model *Model
+	// Score ranks the results. Matches in names score higher than matches in descriptions.
+	Score int
+	// Terms is the number of words in the query that matched.
+	Terms int
+	// Fields lists the fields the query matched, from the highest weight to the lowest.
+	Fields []string
+}
+
+// searchField is a field of a model that search matches, with the weight of a match.
+type searchField struct {
+	name   string
+	weight int
+	values func(summary *azuremodels.ModelSummary, details *azuremodels.ModelDetails) []string
+}
+
+// searchFields lists the fields that search matches, from the highest weight to the lowest. Details are never nil here.
+var searchFields = []searchField{
+	{"name", 10, func(s *azuremodels.ModelSummary, _ *azuremodels.ModelDetails) []string {
+		return []string{s.Name, s.FriendlyName}
+	}},
+	{"publisher", 5, func(s *azuremodels.ModelSummary, _ *azuremodels.ModelDetails) []string {
+		return []string{s.Publisher}
+	}},
+	{"tags", 5, func(_ *azuremodels.ModelSummary, d *azuremodels.ModelDetails) []string {
+		return d.Tags
+	}},
+	{"summary", 3, func(s *azuremodels.ModelSummary, _ *azuremodels.ModelDetails) []string {
+		return []string{s.Summary}
+	}},
+	{"languages", 3, func(_ *azuremodels.ModelSummary, d *azuremodels.ModelDetails) []string {
+		return d.SupportedLanguages
+	}},
+	{"input types", 3, func(_ *azuremodels.ModelSummary, d *azuremodels.ModelDetails) []string {
+		return d.SupportedInputModalities
+	}},
+	{"description", 1, func(_ *azuremodels.ModelSummary, d *azuremodels.ModelDetails) []string {
+		return []string{d.Description}
+	}},
+}
+
+// Search returns the models that pass the query's filters and match at least one word of its text, ranked by the
+// number of words they match, and then by score. If the query has no text, every model that passes the filters is
+// returned, sorted by name.
+func Search(models []*Model, query Query) []Result {
+	terms := queryTerms(query.Text)
+
+	results := []Result{}
+	for _, model := range models {
+		details := model.Details
+		if details == nil {
+			details = &azuremodels.ModelDetails{}
+		}
+		if !query.matchesFilters(model.Summary, details) {
+			continue
+		}
+
+		result := Result{Model: model}
+		matchedFields := map[string]bool{}
+		for _, term := range terms {
+			matched := false
+			for _, field := range searchFields {
+				if containsFold(field.values(model.Summary, details), term) {
+					result.Score += field.weight
+					matchedFields[field.name] = true
+					matched = true
+				}
+			}
+			if matched {
+				result.Terms++
+			}
+		}
+		if len(terms) > 0 && result.Terms == 0 {
+			continue
+		}
+
+		for _, field := range searchFields {
+			if matchedFields[field.name] {
+				result.Fields = append(result.Fields, field.name)
+			}
+		}
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Terms != results[j].Terms {
+			return results[i].Terms > results[j].Terms
+		}
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return strings.ToLower(results[i].Model.Summary.FriendlyName) < strings.ToLower(results[j].Model.Summary.FriendlyName)
+	})
+	return results
+}
+
+// queryTerms splits the text of a query into lowercase words, without stop words.
+func queryTerms(text string) []string {
+	terms := []string{}
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		word = strings.Trim(word, `.,;:!?"'()`)
+		if word != "" && !stopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+func (q Query) matchesFilters(summary *azuremodels.ModelSummary, details *azuremodels.ModelDetails) bool {
+	if q.Publisher != "" && !containsFold([]string{summary.Publisher}, strings.ToLower(q.Publisher)) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !equalFoldAny(details.Tags, tag) {
+			return false
+		}
+	}
+	if q.Language != "" && !supportsLanguage(details.SupportedLanguages, q.Language) {
+		return false
+	}
+	if q.InputType != "" && !equalFoldAny(details.SupportedInputModalities, q.InputType) {
+		return false
+	}
+	return true
+}
+
+// equalFoldAny returns true if any of the values equals the given value, ignoring case.
+func equalFoldAny(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold returns true if any of the values contains the given lowercase substring, ignoring case.
+func containsFold(values []string, substr string) bool {
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	models := []*Model{
+		{
+			Summary: &azuremodels.ModelSummary{Name: "phi-4-multimodal", FriendlyName: "Phi-4 multimodal", Publisher: "Microsoft", Summary: "A small multimodal model"},
+			Details: &azuremodels.ModelDetails{
+				Tags:                     []string{"multilingual", "small"},
+				SupportedInputModalities: []string{"text", "image", "audio"},
+				SupportedLanguages:       []string{"English", "Spanish"},
+			},
+		},
+		{
+			Summary: &azuremodels.ModelSummary{Name: "gpt-4o", FriendlyName: "OpenAI GPT-4o", Publisher: "OpenAI", Summary: "Multimodal, with vision"},
+			Details: &azuremodels.ModelDetails{
+				SupportedInputModalities: []string{"text", "image"},
+				SupportedLanguages:       []string{"English"},
+				Description:              "A large multilingual model.",
+			},
+		},
+		{
+			Summary: &azuremodels.ModelSummary{Name: "llama-3", FriendlyName: "Llama 3", Publisher: "Meta"},
+		},
+	}
+
+	names := func(results []Result) []string {
+		names := []string{}
+		for _, result := range results {
+			names = append(names, result.Model.Summary.Name)
+		}
+		return names
+	}
+
+	t.Run("ranks models by the words they match, then by where", func(t *testing.T) {
+		results := Search(models, Query{Text: "a small multilingual model with image"})
+
+		require.Equal(t, []string{"phi-4-multimodal", "gpt-4o"}, names(results))
+		require.Equal(t, 3, results[0].Terms)
+		require.Equal(t, []string{"tags", "summary", "input types"}, results[0].Fields)
+		require.Equal(t, []string{"input types", "description"}, results[1].Fields)
+	})
+
+	t.Run("applies filters", func(t *testing.T) {
+		require.Equal(t, []string{"phi-4-multimodal"}, names(Search(models, Query{Text: "multimodal", Language: "spanish"})))
+		require.Equal(t, []string{"gpt-4o"}, names(Search(models, Query{Publisher: "openai"})))
+		require.Equal(t, []string{"gpt-4o", "phi-4-multimodal"}, names(Search(models, Query{InputType: "Image"})))
+		require.Empty(t, Search(models, Query{Tags: []string{"small", "large"}}))
+	})
+
+	t.Run("ignores words that match nothing", func(t *testing.T) {
+		require.Equal(t, []string{"llama-3"}, names(Search(models, Query{Text: "llama unicorn"})))
+		require.Empty(t, Search(models, Query{Text: "unicorn"}))
+	})
+}
@@ -0,0 +1,170 @@
+// Package picker provides the interactive prompt for selecting a model, shared by the commands that take a model.
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/github/gh-models/internal/azuremodels"
+)
+
+const (
+	pageSize = 10
+	// maxSummaryLength is the number of characters of the model's summary shown in the preview.
+	maxSummaryLength = 100
+)
+
+// pickerTemplate is survey's select template, changed to preview the details of the highlighted model below it
+// rather than describing every option.
+const pickerTemplate = `
+{{- define "option"}}
+    {{- if eq .SelectedIndex .CurrentIndex }}{{color .Config.Icons.SelectFocus.Format }}{{ .Config.Icons.SelectFocus.Text }} {{else}}{{color "default"}}  {{end}}
+    {{- .CurrentOpt.Value}}
+    {{- if and (eq .SelectedIndex .CurrentIndex) (ne ($.GetDescription .CurrentOpt) "") }}{{color "reset"}}{{"\n"}}{{color "cyan"}}{{ $.GetDescription .CurrentOpt }}{{end}}
+    {{- color "reset"}}
+{{end}}
+{{- if .ShowHelp }}{{- color .Config.Icons.Help.Format }}{{ .Config.Icons.Help.Text }} {{ .Help }}{{color "reset"}}{{"\n"}}{{end}}
+{{- color .Config.Icons.Question.Format }}{{ .Config.Icons.Question.Text }} {{color "reset"}}
+{{- color "default+hb"}}{{ .Message }}{{ .FilterMessage }}{{color "reset"}}
+{{- if .ShowAnswer}}{{color "cyan"}} {{.Answer}}{{color "reset"}}{{"\n"}}
+{{- else}}
+  {{- "  "}}{{- color "cyan"}}[Use arrows to move, type to filter]{{color "reset"}}
+  {{- "\n"}}
+  {{- range $ix, $option := .PageEntries}}
+    {{- template "option" $.IterateOption $ix $option}}
+  {{- end}}
+{{- end}}`
+
+// Picker prompts to select one of the chat models, with the recently used models first. Typing filters the models by
+// a fuzzy match on their names and publisher.
+type Picker struct {
+	models      []*azuremodels.ModelSummary
+	recent      int
+	contextSize func(*azuremodels.ModelSummary) int
+}
+
+// New returns a picker for the chat models among the given models, in the order they are given, after the recently
+// used models with the given names. contextSize returns the input token limit of a model for the preview, or 0 if it
+// is unknown; it can be nil.
+func New(models []*azuremodels.ModelSummary, recent []string, contextSize func(*azuremodels.ModelSummary) int) *Picker {
+	p := &Picker{contextSize: contextSize}
+
+	chatModels := []*azuremodels.ModelSummary{}
+	for _, model := range models {
+		if model.IsChatModel() {
+			chatModels = append(chatModels, model)
+		}
+	}
+
+	picked := map[*azuremodels.ModelSummary]bool{}
+	for _, name := range recent {
+		for _, model := range chatModels {
+			if !picked[model] && model.HasName(name) {
+				picked[model] = true
+				p.models = append(p.models, model)
+				break
+			}
+		}
+	}
+	p.recent = len(p.models)
+
+	for _, model := range chatModels {
+		if !picked[model] {
+			p.models = append(p.models, model)
+		}
+	}
+	return p
+}
+
+// Pick prompts for a model and returns the selected one.
+func (p *Picker) Pick() (*azuremodels.ModelSummary, error) {
+	if len(p.models) == 0 {
+		return nil, errors.New("no models are available to select from")
+	}
+
+	prompt := &survey.Select{
+		Message:     "Select a model:",
+		Options:     p.options(),
+		Filter:      p.filter,
+		Description: p.preview,
+	}
+
+	// survey has no option for the template of a single prompt, so it is swapped for the duration of this prompt.
+	defaultTemplate := survey.SelectQuestionTemplate
+	survey.SelectQuestionTemplate = pickerTemplate
+	defer func() { survey.SelectQuestionTemplate = defaultTemplate }()
+
+	index := 0
+	err := survey.AskOne(prompt, &index, survey.WithPageSize(pageSize))
+	if err != nil {
+		return nil, err
+	}
+	return p.models[index], nil
+}
+
+// options returns the label of each model in the prompt.
+func (p *Picker) options() []string {
+	options := make([]string, len(p.models))
+	for i, model := range p.models {
+		options[i] = model.FriendlyName
+		if i < p.recent {
+			options[i] += " (recent)"
+		}
+	}
+	return options
+}
+
+// filter returns true if the model at the given index matches what has been typed.
+func (p *Picker) filter(filter string, _ string, index int) bool {
+	model := p.models[index]
+	return fuzzyMatch(filter, model.FriendlyName) || fuzzyMatch(filter, model.Name) || fuzzyMatch(filter, model.Publisher)
+}
+
+// preview describes the model at the given index: its publisher, context size and summary.
+func (p *Picker) preview(_ string, index int) string {
+	model := p.models[index]
+
+	facts := []string{}
+	if model.Publisher != "" {
+		facts = append(facts, model.Publisher)
+	}
+	if p.contextSize != nil {
+		if tokens := p.contextSize(model); tokens > 0 {
+			facts = append(facts, fmt.Sprintf("%d input tokens", tokens))
+		}
+	}
+
+	lines := []string{}
+	if len(facts) > 0 {
+		lines = append(lines, "    "+strings.Join(facts, " · "))
+	}
+	if summary := truncate(strings.Join(strings.Fields(model.Summary), " "), maxSummaryLength); summary != "" {
+		lines = append(lines, "    "+summary)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fuzzyMatch returns true if the characters of the pattern appear in the value in order, ignoring case and spaces, so
+// that "g4m" matches "gpt-4o-mini".
+func fuzzyMatch(pattern, value string) bool {
+	pattern = strings.ToLower(strings.Join(strings.Fields(pattern), ""))
+	value = strings.ToLower(value)
+	for _, r := range pattern {
+		i := strings.IndexRune(value, r)
+		if i < 0 {
+			return false
+		}
+		value = value[i+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+func truncate(s string, length int) string {
+	if utf8.RuneCountInString(s) <= length {
+		return s
+	}
+	return string([]rune(s)[:length-1]) + "…"
+}
@@ -0,0 +1,58 @@
+package picker
+
+import (
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPicker(t *testing.T) {
+	models := []*azuremodels.ModelSummary{
+		{Name: "gpt-4o", FriendlyName: "OpenAI GPT-4o", Publisher: "OpenAI", Task: "chat-completion", Summary: "OpenAI's most\nadvanced model."},
+		{Name: "gpt-4o-mini", FriendlyName: "OpenAI GPT-4o mini", Publisher: "OpenAI", Task: "chat-completion"},
+		{Name: "text-embedding-3-small", FriendlyName: "Text Embedding 3 (small)", Task: "embeddings"},
+		{Name: "Phi-4", FriendlyName: "Phi-4", Publisher: "Microsoft", Task: "chat-completion"},
+	}
+
+	t.Run("offers the chat models, recently used ones first", func(t *testing.T) {
+		p := New(models, []string{"phi-4", "missing"}, nil)
+
+		require.Equal(t, []string{"Phi-4 (recent)", "OpenAI GPT-4o", "OpenAI GPT-4o mini"}, p.options())
+	})
+
+	t.Run("filters by a fuzzy match on the name or publisher", func(t *testing.T) {
+		p := New(models, nil, nil)
+
+		matches := func(filter string) []string {
+			names := []string{}
+			for i, model := range p.models {
+				if p.filter(filter, "", i) {
+					names = append(names, model.Name)
+				}
+			}
+			return names
+		}
+		require.Equal(t, []string{"gpt-4o-mini"}, matches("g4mini"))
+		require.Equal(t, []string{"Phi-4"}, matches("micro"))
+		require.Equal(t, []string{"gpt-4o", "gpt-4o-mini", "Phi-4"}, matches(""))
+		require.Empty(t, matches("llama"))
+	})
+
+	t.Run("previews the publisher, context size and summary", func(t *testing.T) {
+		p := New(models, nil, func(model *azuremodels.ModelSummary) int {
+			if model.Name == "gpt-4o" {
+				return 128000
+			}
+			return 0
+		})
+
+		require.Equal(t, "    OpenAI · 128000 input tokens\n    OpenAI's most advanced model.", p.preview("", 0))
+		require.Equal(t, "    OpenAI", p.preview("", 1))
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		require.Equal(t, "short", truncate("short", 10))
+		require.Equal(t, "a long…", truncate("a long summary", 7))
+	})
+}
@@ -0,0 +1,51 @@
+package picker
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/config"
+)
+
+// maxRecent is the number of recently used models that are remembered.
+const maxRecent = 5
+
+// RecentPath returns the path of the list of recently used models in the gh state directory.
+func RecentPath() string {
+	return filepath.Join(config.StateDir(), "gh-models", "recent-models.json")
+}
+
+// ReadRecent returns the names of the recently used models in the file at the given path, most recent first. A
+// missing or unreadable file is treated as an empty list, since the list is only a convenience.
+func ReadRecent(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return nil
+	}
+	return names
+}
+
+// AddRecent moves the model with the given name to the top of the list of recently used models at the given path.
+func AddRecent(path, name string) error {
+	names := []string{name}
+	for _, recent := range ReadRecent(path) {
+		if !strings.EqualFold(recent, name) && len(names) < maxRecent {
+			names = append(names, recent)
+		}
+	}
+
+	data, err := json.Marshal(names)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,25 @@
+package picker
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecent(t *testing.T) {
+	t.Run("ReadRecent returns nothing if the file doesn't exist", func(t *testing.T) {
+		require.Empty(t, ReadRecent(filepath.Join(t.TempDir(), "recent-models.json")))
+	})
+
+	t.Run("AddRecent moves the model to the top and keeps the most recent models", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gh-models", "recent-models.json")
+		for i := 1; i <= 6; i++ {
+			require.NoError(t, AddRecent(path, "model-"+strconv.Itoa(i)))
+		}
+		require.NoError(t, AddRecent(path, "MODEL-4"))
+
+		require.Equal(t, []string{"MODEL-4", "model-6", "model-5", "model-3", "model-2"}, ReadRecent(path))
+	})
+}
@@ -0,0 +1,177 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/sse"
+	"github.com/github/gh-models/internal/tokens"
+)
+
+// Client reserves chat completions in the budget of the model's rate limit tier before sending them, and records the
+// tokens they used once they have streamed in.
+//
+// The tier of a model is taken from its details. The models and details that pass through the client are remembered,
+// so a model whose details were already fetched, as run does, costs no extra request, and otherwise only that model's
+// details are fetched.
+type Client struct {
+	client  azuremodels.Client
+	tracker *Tracker
+	warn    func(message string)
+
+	mu     sync.Mutex
+	models []*azuremodels.ModelSummary
+	tiers  map[string]string
+}
+
+// NewClient returns a new client that sends requests to the given client, tracking them with the tracker. Requests to
+// models whose tier is unknown aren't tracked. warn is called with warnings that little of a budget is left.
+func NewClient(client azuremodels.Client, tracker *Tracker, warn func(message string)) *Client {
+	return &Client{client: client, tracker: tracker, warn: warn, tiers: map[string]string{}}
+}
+
+// GetChatCompletionStream returns a stream of chat completions, or a *BudgetExceededError without sending the request
+// if it would exceed the budget of the model's tier.
+func (c *Client) GetChatCompletionStream(ctx context.Context, req azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+	tier := c.tier(ctx, req.Model)
+	if tier == "" {
+		return c.client.GetChatCompletionStream(ctx, req)
+	}
+
+	promptTokens := tokens.EstimateMessages(req.Messages)
+	warning, err := c.tracker.Reserve(tier, promptTokens)
+	if err != nil {
+		return nil, err
+	}
+	if warning != "" && c.warn != nil {
+		c.warn(warning)
+	}
+
+	resp, err := c.client.GetChatCompletionStream(ctx, req)
+	if err != nil {
+		// The request wasn't answered, so it doesn't use the budget. Failing to release it only undercounts what's left.
+		_ = c.tracker.Release(tier, promptTokens)
+		return nil, err
+	}
+
+	resp.Reader = &usageReader{
+		reader:       resp.Reader,
+		promptTokens: promptTokens,
+		record: func(tokens int) {
+			// Failing to record usage shouldn't fail the request, which has already been answered.
+			_ = c.tracker.Record(tier, promptTokens, tokens)
+		},
+	}
+	return resp, nil
+}
+
+// GetModelDetails returns the details of the specified model.
+func (c *Client) GetModelDetails(ctx context.Context, registry, modelName, version string) (*azuremodels.ModelDetails, error) {
+	details, err := c.client.GetModelDetails(ctx, registry, modelName, version)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.tiers[strings.ToLower(modelName)] = strings.ToLower(details.RateLimitTier)
+	c.mu.Unlock()
+	return details, nil
+}
+
+// ListModels returns a list of available models.
+func (c *Client) ListModels(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+	models, err := c.client.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.models = models
+	c.mu.Unlock()
+	return models, nil
+}
+
+// ListModelVersions returns every version of the available models.
+func (c *Client) ListModelVersions(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+	return c.client.ListModelVersions(ctx)
+}
+
+// tier returns the rate limit tier of the given model, or "" if it's unknown.
+func (c *Client) tier(ctx context.Context, modelName string) string {
+	c.mu.Lock()
+	tier, ok := c.tiers[strings.ToLower(modelName)]
+	models := c.models
+	c.mu.Unlock()
+	if ok {
+		return tier
+	}
+
+	if models == nil {
+		var err error
+		if models, err = c.ListModels(ctx); err != nil {
+			return ""
+		}
+	}
+	for _, model := range models {
+		if !strings.EqualFold(model.Name, modelName) {
+			continue
+		}
+		details, err := c.GetModelDetails(ctx, model.RegistryName, model.Name, model.Version)
+		if err != nil {
+			return ""
+		}
+		return strings.ToLower(details.RateLimitTier)
+	}
+	return ""
+}
+
+// usageReader records the tokens a response used once it has been read or closed. The usage reported by the model is
+// used if there is one, and otherwise the tokens are estimated.
+type usageReader struct {
+	reader       sse.Reader[azuremodels.ChatCompletion]
+	promptTokens int
+	record       func(tokens int)
+
+	usage      *azuremodels.ChatCompletionUsage
+	completion int
+	once       sync.Once
+}
+
+func (r *usageReader) Read() (azuremodels.ChatCompletion, error) {
+	completion, err := r.reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			r.done()
+		}
+		return completion, err
+	}
+
+	if completion.Usage != nil {
+		r.usage = completion.Usage
+	}
+	for _, choice := range completion.Choices {
+		if choice.Delta != nil && choice.Delta.Content != nil {
+			r.completion += tokens.Estimate(*choice.Delta.Content)
+		} else if choice.Message != nil && choice.Message.Content != nil {
+			r.completion += tokens.Estimate(*choice.Message.Content)
+		}
+	}
+	return completion, nil
+}
+
+func (r *usageReader) Close() error {
+	r.done()
+	return r.reader.Close()
+}
+
+func (r *usageReader) done() {
+	r.once.Do(func() {
+		if r.usage != nil && r.usage.TotalTokens > 0 {
+			r.record(r.usage.TotalTokens)
+		} else {
+			r.record(r.promptTokens + r.completion)
+		}
+	})
+}
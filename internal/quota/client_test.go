@@ -0,0 +1,184 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/sse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	content := "The sky is blue."
+	// lookups counts the calls made to find the tiers of models.
+	type lookups struct {
+		list    int
+		details int
+	}
+	newClient := func(t *testing.T, budgets map[string]Budget, usage *azuremodels.ChatCompletionUsage) (*Client, *Tracker, *int, *[]string) {
+		calls := 0
+		client := azuremodels.NewMockClient()
+		client.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			return []*azuremodels.ModelSummary{{Name: "gpt-4o", RegistryName: "azure-openai", Version: "1"}, {Name: "phi-4"}}, nil
+		}
+		client.MockGetModelDetails = func(ctx context.Context, registry, modelName, version string) (*azuremodels.ModelDetails, error) {
+			if modelName == "gpt-4o" {
+				return &azuremodels.ModelDetails{RateLimitTier: "High"}, nil
+			}
+			return &azuremodels.ModelDetails{}, nil
+		}
+		client.MockGetChatCompletionStream = func(ctx context.Context, req azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			calls++
+			return &azuremodels.ChatCompletionResponse{
+				Reader: sse.NewMockEventReader([]azuremodels.ChatCompletion{{
+					Choices: []azuremodels.ChatChoice{{Message: &azuremodels.ChatChoiceMessage{Content: &content}}},
+					Usage:   usage,
+				}}),
+			}, nil
+		}
+		tracker := newTestTracker(t, budgets)
+		warnings := []string{}
+		quotaClient := NewClient(client, tracker, func(message string) {
+			warnings = append(warnings, message)
+		})
+		return quotaClient, tracker, &calls, &warnings
+	}
+
+	countLookups := func(client *Client) *lookups {
+		counted := &lookups{}
+		mock := client.client.(*azuremodels.MockClient)
+		listModels, getModelDetails := mock.MockListModels, mock.MockGetModelDetails
+		mock.MockListModels = func(ctx context.Context) ([]*azuremodels.ModelSummary, error) {
+			counted.list++
+			return listModels(ctx)
+		}
+		mock.MockGetModelDetails = func(ctx context.Context, registry, modelName, version string) (*azuremodels.ModelDetails, error) {
+			counted.details++
+			return getModelDetails(ctx, registry, modelName, version)
+		}
+		return counted
+	}
+
+	readAll := func(t *testing.T, resp *azuremodels.ChatCompletionResponse) {
+		for {
+			_, err := resp.Reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+		}
+		require.NoError(t, resp.Reader.Close())
+	}
+
+	t.Run("records the usage reported by the model once", func(t *testing.T) {
+		client, tracker, _, _ := newClient(t, DefaultBudgets, &azuremodels.ChatCompletionUsage{TotalTokens: 42})
+
+		resp, err := client.GetChatCompletionStream(context.Background(), azuremodels.ChatCompletionOptions{Model: "gpt-4o"})
+		require.NoError(t, err)
+		readAll(t, resp)
+
+		usage, err := tracker.Usage()
+		require.NoError(t, err)
+		require.Equal(t, &TierUsage{Requests: 1, Tokens: 42}, usage.Tiers["high"])
+	})
+
+	t.Run("estimates the usage when the model doesn't report it", func(t *testing.T) {
+		client, tracker, _, _ := newClient(t, DefaultBudgets, nil)
+
+		resp, err := client.GetChatCompletionStream(context.Background(), azuremodels.ChatCompletionOptions{Model: "gpt-4o"})
+		require.NoError(t, err)
+		readAll(t, resp)
+
+		usage, err := tracker.Usage()
+		require.NoError(t, err)
+		require.Equal(t, 1, usage.Tiers["high"].Requests)
+		require.Positive(t, usage.Tiers["high"].Tokens)
+	})
+
+	t.Run("refuses requests over the budget without sending them", func(t *testing.T) {
+		client, tracker, calls, _ := newClient(t, map[string]Budget{"high": {RequestsPerDay: 1}}, nil)
+		reserve(t, tracker, "high", 10)
+
+		_, err := client.GetChatCompletionStream(context.Background(), azuremodels.ChatCompletionOptions{Model: "gpt-4o"})
+
+		var budgetErr *BudgetExceededError
+		require.ErrorAs(t, err, &budgetErr)
+		require.Equal(t, "high", budgetErr.Tier)
+		require.Zero(t, *calls)
+	})
+
+	t.Run("releases the reservation of requests that fail to send", func(t *testing.T) {
+		client, tracker, _, _ := newClient(t, map[string]Budget{"high": {RequestsPerDay: 1}}, nil)
+		client.client.(*azuremodels.MockClient).MockGetChatCompletionStream = func(ctx context.Context, req azuremodels.ChatCompletionOptions) (*azuremodels.ChatCompletionResponse, error) {
+			return nil, errors.New("connection refused")
+		}
+
+		_, err := client.GetChatCompletionStream(context.Background(), azuremodels.ChatCompletionOptions{Model: "gpt-4o"})
+		require.EqualError(t, err, "connection refused")
+
+		usage, err := tracker.Usage()
+		require.NoError(t, err)
+		require.Equal(t, &TierUsage{}, usage.Tiers["high"])
+	})
+
+	t.Run("warns when little of the budget is left", func(t *testing.T) {
+		client, _, calls, warnings := newClient(t, map[string]Budget{"high": {RequestsPerDay: 1}}, nil)
+
+		_, err := client.GetChatCompletionStream(context.Background(), azuremodels.ChatCompletionOptions{Model: "gpt-4o"})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, *calls)
+		require.Equal(t, []string{"Warning: 0 of 1 daily requests left for the high rate limit tier after this one.\n"}, *warnings)
+	})
+
+	t.Run("looks up the tier of each model once", func(t *testing.T) {
+		client, tracker, _, _ := newClient(t, DefaultBudgets, nil)
+		counted := countLookups(client)
+
+		for i := 0; i < 2; i++ {
+			resp, err := client.GetChatCompletionStream(context.Background(), azuremodels.ChatCompletionOptions{Model: "gpt-4o"})
+			require.NoError(t, err)
+			readAll(t, resp)
+		}
+
+		require.Equal(t, &lookups{list: 1, details: 1}, counted)
+		usage, err := tracker.Usage()
+		require.NoError(t, err)
+		require.Equal(t, 2, usage.Tiers["high"].Requests)
+	})
+
+	t.Run("reuses the models and details that were already fetched", func(t *testing.T) {
+		client, _, _, _ := newClient(t, DefaultBudgets, nil)
+		ctx := context.Background()
+		_, err := client.ListModels(ctx)
+		require.NoError(t, err)
+		_, err = client.GetModelDetails(ctx, "azure-openai", "gpt-4o", "1")
+		require.NoError(t, err)
+		counted := countLookups(client)
+
+		_, err = client.GetChatCompletionStream(ctx, azuremodels.ChatCompletionOptions{Model: "gpt-4o"})
+
+		require.NoError(t, err)
+		require.Equal(t, &lookups{}, counted)
+	})
+
+	t.Run("doesn't track models without a tier", func(t *testing.T) {
+		client, tracker, calls, _ := newClient(t, map[string]Budget{"high": {RequestsPerDay: 1}}, nil)
+
+		resp, err := client.GetChatCompletionStream(context.Background(), azuremodels.ChatCompletionOptions{Model: "phi-4"})
+		require.NoError(t, err)
+		readAll(t, resp)
+
+		resp, err = client.GetChatCompletionStream(context.Background(), azuremodels.ChatCompletionOptions{Model: "unknown"})
+		require.NoError(t, err)
+		readAll(t, resp)
+
+		usage, err := tracker.Usage()
+		require.NoError(t, err)
+		require.Empty(t, usage.Tiers)
+		require.Equal(t, 2, *calls)
+	})
+}
@@ -0,0 +1,15 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package quota
+
+import "os"
+
+// lockFile does nothing on platforms without file locks, where only requests from the same process are serialized.
+func lockFile(f *os.File) error {
+	return nil
+}
+
+// unlockFile does nothing on platforms without file locks.
+func unlockFile(f *os.File) error {
+	return nil
+}
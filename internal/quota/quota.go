@@ -0,0 +1,302 @@
+// Package quota tracks the requests and tokens used in each rate limit tier of GitHub Models, so that a request which
+// would exceed the daily budget of its tier is refused before it's sent, rather than failing partway through a job.
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/config"
+)
+
+const (
+	// dayFormat is the format of the day that usage was recorded on. Budgets reset at midnight UTC.
+	dayFormat = "2006-01-02"
+	// warningFraction is the fraction of a budget that is left when requests start to warn about it.
+	warningFraction = 0.1
+)
+
+// Budget is the daily allowance of a rate limit tier. A limit of 0 means there is no limit.
+type Budget struct {
+	RequestsPerDay int
+	TokensPerDay   int
+}
+
+// DefaultBudgets are the daily request limits of the free rate limit tiers.
+var DefaultBudgets = map[string]Budget{
+	"low":    {RequestsPerDay: 150},
+	"high":   {RequestsPerDay: 50},
+	"custom": {RequestsPerDay: 8},
+}
+
+// TierUsage is what has been used of a tier's budget.
+type TierUsage struct {
+	Requests int `json:"requests"`
+	Tokens   int `json:"tokens"`
+}
+
+// Usage is what has been used of each tier's budget on one day.
+type Usage struct {
+	Day   string                `json:"day"`
+	Tiers map[string]*TierUsage `json:"tiers"`
+}
+
+// TierStatus is the budget of a tier and how much of it has been used today.
+type TierStatus struct {
+	Tier   string
+	Budget Budget
+	Used   TierUsage
+}
+
+// RemainingRequests returns the number of requests left today, or -1 if there is no limit.
+func (s TierStatus) RemainingRequests() int {
+	return remaining(s.Budget.RequestsPerDay, s.Used.Requests)
+}
+
+// RemainingTokens returns the number of tokens left today, or -1 if there is no limit.
+func (s TierStatus) RemainingTokens() int {
+	return remaining(s.Budget.TokensPerDay, s.Used.Tokens)
+}
+
+func remaining(limit, used int) int {
+	if limit == 0 {
+		return -1
+	}
+	return max(limit-used, 0)
+}
+
+// BudgetExceededError is returned by Reserve when a request would exceed the daily budget of its tier.
+type BudgetExceededError struct {
+	Tier  string
+	Limit int
+	// Unit is what the budget limits: "requests" or "tokens".
+	Unit string
+}
+
+// Retryable returns true, since the request may be within the budget of another model's tier.
+func (e *BudgetExceededError) Retryable() bool {
+	return true
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("this request would exceed the daily budget of %d %s for the %s rate limit tier. The budget resets at midnight UTC. Run 'gh models usage' to see the remaining budgets, or change the budget with 'gh models config set budgets.%s.%s-per-day <limit>'",
+		e.Limit, e.Unit, e.Tier, e.Tier, e.Unit)
+}
+
+// UsagePath returns the path of the usage of the given GitHub host in the gh state directory.
+func UsagePath(host string) string {
+	if host == "" {
+		host = "default"
+	}
+	return filepath.Join(config.StateDir(), "gh-models", "usage-"+host+".json")
+}
+
+// Tracker records usage in a file, so that it's shared between invocations, and checks requests against the budgets.
+// Usage is recorded under a lock, so that concurrent requests, from this process or others, are all counted.
+type Tracker struct {
+	path    string
+	budgets map[string]Budget
+	now     func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewTracker returns a new tracker that records usage at the given path, with the given budgets by tier. Tiers without
+// a budget are tracked, but not limited.
+func NewTracker(path string, budgets map[string]Budget) *Tracker {
+	return &Tracker{path: path, budgets: budgets, now: time.Now}
+}
+
+// Usage returns today's usage. Usage recorded on earlier days is discarded.
+func (t *Tracker) Usage() (*Usage, error) {
+	today := t.now().UTC().Format(dayFormat)
+	usage := &Usage{Day: today, Tiers: map[string]*TierUsage{}}
+
+	data, err := os.ReadFile(t.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return usage, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded Usage
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return nil, fmt.Errorf("failed to read the usage at %s: %w", t.path, err)
+	}
+	if recorded.Day == today && recorded.Tiers != nil {
+		return &recorded, nil
+	}
+	return usage, nil
+}
+
+// Status returns the budget and today's usage of each tier that has a budget or has been used, sorted by tier.
+func (t *Tracker) Status() ([]TierStatus, error) {
+	usage, err := t.Usage()
+	if err != nil {
+		return nil, err
+	}
+
+	tiers := map[string]bool{}
+	for tier := range t.budgets {
+		tiers[tier] = true
+	}
+	for tier := range usage.Tiers {
+		tiers[tier] = true
+	}
+
+	statuses := make([]TierStatus, 0, len(tiers))
+	for tier := range tiers {
+		status := TierStatus{Tier: tier, Budget: t.budgets[tier]}
+		if used := usage.Tiers[tier]; used != nil {
+			status.Used = *used
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Tier < statuses[j].Tier })
+	return statuses, nil
+}
+
+// Reserve returns a *BudgetExceededError if a request in the given tier, using about the given number of tokens,
+// would exceed the tier's daily budget. Otherwise, it adds the request and its tokens to today's usage, and returns a
+// warning if little of the budget is left, or "". The check and the reservation are made under one lock, so that
+// concurrent requests can't all pass the check before any of them is counted.
+//
+// Once the request has been answered, Record corrects the reserved tokens with those it used, and if it isn't sent,
+// Release gives the reservation back.
+func (t *Tracker) Reserve(tier string, tokens int) (string, error) {
+	var warning string
+	err := t.update(func(usage *Usage) error {
+		used := usage.Tiers[tier]
+		if used == nil {
+			used = &TierUsage{}
+		}
+		budget := t.budgets[tier]
+
+		if budget.RequestsPerDay > 0 && used.Requests+1 > budget.RequestsPerDay {
+			return &BudgetExceededError{Tier: tier, Limit: budget.RequestsPerDay, Unit: "requests"}
+		}
+		if budget.TokensPerDay > 0 && used.Tokens+tokens > budget.TokensPerDay {
+			return &BudgetExceededError{Tier: tier, Limit: budget.TokensPerDay, Unit: "tokens"}
+		}
+
+		if left := budget.RequestsPerDay - used.Requests - 1; budget.RequestsPerDay > 0 && float64(left) < float64(budget.RequestsPerDay)*warningFraction {
+			warning = fmt.Sprintf("Warning: %d of %d daily requests left for the %s rate limit tier after this one.\n", left, budget.RequestsPerDay, tier)
+		} else if left := budget.TokensPerDay - used.Tokens - tokens; budget.TokensPerDay > 0 && float64(left) < float64(budget.TokensPerDay)*warningFraction {
+			warning = fmt.Sprintf("Warning: about %d of %d daily tokens left for the %s rate limit tier after this request.\n", left, budget.TokensPerDay, tier)
+		}
+
+		used.Requests++
+		used.Tokens += tokens
+		usage.Tiers[tier] = used
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return warning, nil
+}
+
+// Record corrects the tokens of a request reserved with Reserve, from the reserved number to the number it used.
+func (t *Tracker) Record(tier string, reserved, tokens int) error {
+	return t.update(func(usage *Usage) error {
+		used := usage.Tiers[tier]
+		if used == nil {
+			// The reservation was made on an earlier day, whose usage has been discarded.
+			return nil
+		}
+		used.Tokens = max(used.Tokens+tokens-reserved, 0)
+		return nil
+	})
+}
+
+// Release gives back a request reserved with Reserve that wasn't sent.
+func (t *Tracker) Release(tier string, reserved int) error {
+	return t.update(func(usage *Usage) error {
+		used := usage.Tiers[tier]
+		if used == nil {
+			return nil
+		}
+		used.Requests = max(used.Requests-1, 0)
+		used.Tokens = max(used.Tokens-reserved, 0)
+		return nil
+	})
+}
+
+// update changes today's usage with the given function under the lock, and writes it unless the function returns an
+// error.
+func (t *Tracker) update(change func(usage *Usage) error) error {
+	unlock, err := t.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	usage, err := t.Usage()
+	if err != nil {
+		return err
+	}
+	if err := change(usage); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(t.path, data)
+}
+
+// lock serializes updates to the usage, both between goroutines and, with a lock file next to the usage, between
+// processes. It returns a function that releases the lock.
+func (t *Tracker) lock() (func(), error) {
+	t.mu.Lock()
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		t.mu.Unlock()
+		return nil, err
+	}
+	f, err := os.OpenFile(t.path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		t.mu.Unlock()
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		t.mu.Unlock()
+		return nil, fmt.Errorf("failed to lock the usage at %s: %w", t.path, err)
+	}
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+		t.mu.Unlock()
+	}, nil
+}
+
+// writeFileAtomic writes the file by renaming a temporary file over it, so that readers never see a partial write.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package quota
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTracker returns a tracker in a temporary directory, on a fixed day.
+func newTestTracker(t *testing.T, budgets map[string]Budget) *Tracker {
+	tracker := NewTracker(filepath.Join(t.TempDir(), "usage.json"), budgets)
+	tracker.now = func() time.Time { return time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC) }
+	return tracker
+}
+
+// reserve reserves a request in the tracker, failing the test if it's refused.
+func reserve(t *testing.T, tracker *Tracker, tier string, tokens int) {
+	_, err := tracker.Reserve(tier, tokens)
+	require.NoError(t, err)
+}
+
+func TestTracker(t *testing.T) {
+	t.Run("Reserve adds up the usage of each tier", func(t *testing.T) {
+		tracker := newTestTracker(t, DefaultBudgets)
+
+		reserve(t, tracker, "high", 100)
+		reserve(t, tracker, "high", 50)
+		reserve(t, tracker, "low", 10)
+
+		usage, err := tracker.Usage()
+		require.NoError(t, err)
+		require.Equal(t, "2024-10-01", usage.Day)
+		require.Equal(t, &TierUsage{Requests: 2, Tokens: 150}, usage.Tiers["high"])
+		require.Equal(t, &TierUsage{Requests: 1, Tokens: 10}, usage.Tiers["low"])
+	})
+
+	t.Run("usage resets at midnight UTC", func(t *testing.T) {
+		tracker := newTestTracker(t, DefaultBudgets)
+		reserve(t, tracker, "high", 100)
+
+		tracker.now = func() time.Time { return time.Date(2024, 10, 2, 0, 30, 0, 0, time.UTC) }
+
+		usage, err := tracker.Usage()
+		require.NoError(t, err)
+		require.Equal(t, "2024-10-02", usage.Day)
+		require.Empty(t, usage.Tiers)
+	})
+
+	t.Run("Reserve refuses requests over the budget", func(t *testing.T) {
+		tracker := newTestTracker(t, map[string]Budget{"high": {RequestsPerDay: 2}, "low": {TokensPerDay: 1000}})
+		reserve(t, tracker, "high", 10)
+		reserve(t, tracker, "high", 10)
+
+		_, err := tracker.Reserve("high", 10)
+		require.Equal(t, &BudgetExceededError{Tier: "high", Limit: 2, Unit: "requests"}, err)
+
+		_, err = tracker.Reserve("low", 1001)
+		require.Equal(t, &BudgetExceededError{Tier: "low", Limit: 1000, Unit: "tokens"}, err)
+
+		warning, err := tracker.Reserve("custom", 1000000)
+		require.NoError(t, err)
+		require.Empty(t, warning)
+
+		usage, err := tracker.Usage()
+		require.NoError(t, err)
+		require.Equal(t, &TierUsage{Requests: 2, Tokens: 20}, usage.Tiers["high"])
+		require.Nil(t, usage.Tiers["low"])
+	})
+
+	t.Run("Reserve warns when little of the budget would be left", func(t *testing.T) {
+		tracker := newTestTracker(t, map[string]Budget{"high": {RequestsPerDay: 10}, "low": {TokensPerDay: 1000}})
+
+		warning, err := tracker.Reserve("high", 10)
+		require.NoError(t, err)
+		require.Empty(t, warning)
+
+		for i := 0; i < 8; i++ {
+			reserve(t, tracker, "high", 10)
+		}
+		warning, err = tracker.Reserve("high", 10)
+		require.NoError(t, err)
+		require.Equal(t, "Warning: 0 of 10 daily requests left for the high rate limit tier after this one.\n", warning)
+
+		warning, err = tracker.Reserve("low", 950)
+		require.NoError(t, err)
+		require.Equal(t, "Warning: about 50 of 1000 daily tokens left for the low rate limit tier after this request.\n", warning)
+	})
+
+	t.Run("Status lists the budgeted and used tiers", func(t *testing.T) {
+		tracker := newTestTracker(t, map[string]Budget{"low": {RequestsPerDay: 150}, "high": {RequestsPerDay: 50}})
+		reserve(t, tracker, "high", 100)
+		reserve(t, tracker, "embeddings", 5)
+
+		statuses, err := tracker.Status()
+
+		require.NoError(t, err)
+		require.Equal(t, []TierStatus{
+			{Tier: "embeddings", Used: TierUsage{Requests: 1, Tokens: 5}},
+			{Tier: "high", Budget: Budget{RequestsPerDay: 50}, Used: TierUsage{Requests: 1, Tokens: 100}},
+			{Tier: "low", Budget: Budget{RequestsPerDay: 150}},
+		}, statuses)
+		require.Equal(t, -1, statuses[0].RemainingRequests())
+		require.Equal(t, 49, statuses[1].RemainingRequests())
+		require.Equal(t, -1, statuses[1].RemainingTokens())
+	})
+
+	t.Run("Record and Release correct reservations", func(t *testing.T) {
+		tracker := newTestTracker(t, DefaultBudgets)
+		reserve(t, tracker, "high", 100)
+		reserve(t, tracker, "high", 50)
+
+		require.NoError(t, tracker.Record("high", 100, 130))
+		require.NoError(t, tracker.Release("high", 50))
+
+		usage, err := tracker.Usage()
+		require.NoError(t, err)
+		require.Equal(t, &TierUsage{Requests: 1, Tokens: 130}, usage.Tiers["high"])
+	})
+
+	t.Run("Reserve never lets concurrent requests exceed the budget", func(t *testing.T) {
+		tracker := newTestTracker(t, map[string]Budget{"high": {RequestsPerDay: 5}})
+		other := NewTracker(tracker.path, tracker.budgets)
+		other.now = tracker.now
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		reserved := 0
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(tracker *Tracker) {
+				defer wg.Done()
+				if _, err := tracker.Reserve("high", 10); err == nil {
+					mu.Lock()
+					reserved++
+					mu.Unlock()
+				}
+			}([]*Tracker{tracker, other}[i%2])
+		}
+		wg.Wait()
+
+		require.Equal(t, 5, reserved)
+		usage, err := tracker.Usage()
+		require.NoError(t, err)
+		require.Equal(t, &TierUsage{Requests: 5, Tokens: 50}, usage.Tiers["high"])
+	})
+
+	t.Run("Reserve counts concurrent requests", func(t *testing.T) {
+		tracker := newTestTracker(t, DefaultBudgets)
+		// A second tracker of the same file stands in for another process.
+		other := NewTracker(tracker.path, DefaultBudgets)
+		other.now = tracker.now
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				reserve(t, tracker, "high", 10)
+			}()
+			go func() {
+				defer wg.Done()
+				reserve(t, other, "high", 5)
+			}()
+		}
+		wg.Wait()
+
+		usage, err := tracker.Usage()
+		require.NoError(t, err)
+		require.Equal(t, &TierUsage{Requests: 40, Tokens: 300}, usage.Tiers["high"])
+
+		files, err := filepath.Glob(filepath.Join(filepath.Dir(tracker.path), "*.tmp"))
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+
+	t.Run("Usage reports invalid files", func(t *testing.T) {
+		tracker := newTestTracker(t, DefaultBudgets)
+		require.NoError(t, os.WriteFile(tracker.path, []byte("{"), 0o600))
+
+		_, err := tracker.Usage()
+
+		require.ErrorContains(t, err, "failed to read the usage at "+tracker.path)
+	})
+}
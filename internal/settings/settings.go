@@ -0,0 +1,634 @@
+// Package settings provides the user's persisted settings for the gh-models extension: default model, system prompt
+// and model parameters, model aliases, and named profiles.
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/config"
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/quota"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// fileName is the name of the settings file in the gh config directory.
+	fileName = "models.yml"
+	// envPrefix is the prefix of the environment variables that override settings.
+	envPrefix = "GH_MODELS_"
+)
+
+// Profile holds the settings used for a model run. The defaults and each named profile are profiles.
+type Profile struct {
+	// Model is the model to run, or an alias for it.
+	Model string `yaml:"model,omitempty"`
+	// Provider is the provider that model names without a provider refer to. If empty, they refer to GitHub Models.
+	Provider string `yaml:"provider,omitempty"`
+	// Fallback lists the models to retry a request on, in order, when the model is rate limited or unavailable.
+	Fallback []string `yaml:"fallback,omitempty"`
+	// SystemPrompt is the system prompt to use.
+	SystemPrompt string `yaml:"system-prompt,omitempty"`
+	// Parameters holds values for model parameters, keyed by the name of their flag.
+	Parameters map[string]string `yaml:"parameters,omitempty"`
+}
+
+// HostEndpoints holds the GitHub Models API URLs for a GitHub host, for hosts whose URLs aren't built in.
+type HostEndpoints struct {
+	// InferenceURL is the URL of the chat completions endpoint.
+	InferenceURL string `yaml:"inference-url,omitempty"`
+	// CatalogURL is the base URL of the model catalog.
+	CatalogURL string `yaml:"catalog-url,omitempty"`
+}
+
+// ProviderSettings describes an additional provider with an OpenAI-compatible API.
+type ProviderSettings struct {
+	// BaseURL is the URL that the API paths, such as /chat/completions, are relative to.
+	BaseURL string `yaml:"base-url,omitempty"`
+	// APIKeyEnv is the name of the environment variable holding the API key, so the key isn't stored in the file.
+	APIKeyEnv string `yaml:"api-key-env,omitempty"`
+	// AuthHeader is the header the API key is sent in, if not the Authorization header.
+	AuthHeader string `yaml:"auth-header,omitempty"`
+}
+
+// BudgetSettings overrides the daily budget of a rate limit tier. Empty values keep the default, and 0 means no limit.
+type BudgetSettings struct {
+	// RequestsPerDay is the number of requests allowed each day.
+	RequestsPerDay string `yaml:"requests-per-day,omitempty"`
+	// TokensPerDay is the number of tokens allowed each day.
+	TokensPerDay string `yaml:"tokens-per-day,omitempty"`
+}
+
+// Settings represents the settings file.
+type Settings struct {
+	// Profile holds the default settings.
+	Profile `yaml:",inline"`
+	// Aliases maps short names to model names.
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+	// Profiles holds named sets of settings, selected with --profile.
+	Profiles map[string]*Profile `yaml:"profiles,omitempty"`
+	// Hosts holds the API URLs for GitHub hosts, keyed by hostname.
+	Hosts map[string]*HostEndpoints `yaml:"hosts,omitempty"`
+	// Providers holds additional model providers, keyed by name.
+	Providers map[string]*ProviderSettings `yaml:"providers,omitempty"`
+	// Budgets overrides the daily budgets of rate limit tiers, keyed by tier.
+	Budgets map[string]*BudgetSettings `yaml:"budgets,omitempty"`
+	// Sort is the order of models in list and the model pickers: one of azuremodels.SortOrders.
+	Sort string `yaml:"sort,omitempty"`
+	// Pinned lists the models shown first in list and the model pickers.
+	Pinned []string `yaml:"pinned,omitempty"`
+	// Featured lists the models shown after the pinned models, such as the models an organisation recommends.
+	Featured []string `yaml:"featured,omitempty"`
+	// Prefer lists the models that run --auto chooses first, in order, when they meet the requirements.
+	Prefer []string `yaml:"prefer,omitempty"`
+
+	path string
+}
+
+// Path returns the path of the settings file in the gh config directory.
+func Path() string {
+	return filepath.Join(config.ConfigDir(), fileName)
+}
+
+// Load loads the settings from the settings file in the gh config directory.
+func Load() (*Settings, error) {
+	return LoadFile(Path())
+}
+
+// LoadFile loads the settings from the given file. A missing file results in empty settings.
+func LoadFile(path string) (*Settings, error) {
+	s := &Settings{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the settings to the file they were loaded from.
+func (s *Settings) Save() error {
+	if s.path == "" {
+		return errors.New("the settings were not loaded from a file, so they can't be saved")
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o600)
+}
+
+// ResolveAlias returns the model name for the given alias, or the name itself if it isn't an alias.
+func (s *Settings) ResolveAlias(name string) string {
+	if model, ok := s.Aliases[name]; ok {
+		return model
+	}
+	return name
+}
+
+// Resolve returns the settings to use for a model run. Settings from the environment take precedence over the named
+// profile, which takes precedence over the defaults. If profileName is empty, the profile named by GH_MODELS_PROFILE is
+// used, if any. Model parameters are not read from the environment here; see EnvName.
+func (s *Settings) Resolve(profileName string) (Profile, error) {
+	resolved := Profile{
+		Model:        s.Model,
+		Provider:     s.Provider,
+		Fallback:     s.Fallback,
+		SystemPrompt: s.SystemPrompt,
+		Parameters:   map[string]string{},
+	}
+	for name, value := range s.Parameters {
+		resolved.Parameters[name] = value
+	}
+
+	if profileName == "" {
+		profileName = os.Getenv(EnvName("profile"))
+	}
+	if profileName != "" {
+		profile, ok := s.Profiles[profileName]
+		if !ok {
+			return Profile{}, fmt.Errorf("unknown profile '%s'. Run 'gh models config list' to see the configured profiles", profileName)
+		}
+		if profile.Model != "" {
+			resolved.Model = profile.Model
+		}
+		if profile.Provider != "" {
+			resolved.Provider = profile.Provider
+		}
+		if len(profile.Fallback) > 0 {
+			resolved.Fallback = profile.Fallback
+		}
+		if profile.SystemPrompt != "" {
+			resolved.SystemPrompt = profile.SystemPrompt
+		}
+		for name, value := range profile.Parameters {
+			resolved.Parameters[name] = value
+		}
+	}
+
+	if model := os.Getenv(EnvName("model")); model != "" {
+		resolved.Model = model
+	}
+	if systemPrompt := os.Getenv(EnvName("system-prompt")); systemPrompt != "" {
+		resolved.SystemPrompt = systemPrompt
+	}
+	if provider := os.Getenv(EnvName("provider")); provider != "" {
+		resolved.Provider = provider
+	}
+	if fallback := os.Getenv(EnvName("fallback")); fallback != "" {
+		resolved.Fallback = splitList(fallback)
+	}
+
+	if resolved.Provider != "" {
+		if _, ok := s.Providers[resolved.Provider]; !ok {
+			return Profile{}, fmt.Errorf("unknown provider '%s'. Add it with 'gh models config set providers.%s.base-url <url>'", resolved.Provider, resolved.Provider)
+		}
+	}
+
+	if resolved.Model != "" {
+		resolved.Model = s.ResolveModel(resolved.Model, resolved)
+	}
+	if len(resolved.Fallback) > 0 {
+		fallback := make([]string, len(resolved.Fallback))
+		for i, name := range resolved.Fallback {
+			fallback[i] = s.ResolveModel(name, resolved)
+		}
+		resolved.Fallback = fallback
+	}
+	return resolved, nil
+}
+
+// ResolveModel returns the model name to request for the given name, which may be an alias. Names without a provider
+// refer to the profile's provider, if it has one. Only a configured provider counts as a prefix, since model names
+// such as "llama3:8b" can contain the separator themselves.
+func (s *Settings) ResolveModel(name string, profile Profile) string {
+	name = s.ResolveAlias(name)
+	if profile.Provider != "" && !s.hasProviderPrefix(name) {
+		name = azuremodels.QualifyModelName(profile.Provider, name)
+	}
+	return name
+}
+
+// hasProviderPrefix returns whether the given model name starts with the name of a configured provider.
+func (s *Settings) hasProviderPrefix(name string) bool {
+	provider, _, ok := strings.Cut(name, azuremodels.ProviderSeparator)
+	if !ok {
+		return false
+	}
+	_, ok = s.Providers[provider]
+	return ok
+}
+
+// EnvName returns the name of the environment variable that overrides the setting with the given name, for example
+// GH_MODELS_SYSTEM_PROMPT for system-prompt.
+func EnvName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// ModelOrder returns the order of models in list and the model pickers. GH_MODELS_SORT, GH_MODELS_PINNED and
+// GH_MODELS_FEATURED take precedence over the file, so organisations can set them for everyone.
+func (s *Settings) ModelOrder() azuremodels.ModelOrder {
+	order := azuremodels.ModelOrder{By: s.Sort, Pinned: s.Pinned, Featured: s.Featured}
+	if sort := os.Getenv(EnvName("sort")); sort != "" {
+		order.By = sort
+	}
+	if pinned := os.Getenv(EnvName("pinned")); pinned != "" {
+		order.Pinned = splitList(pinned)
+	}
+	if featured := os.Getenv(EnvName("featured")); featured != "" {
+		order.Featured = splitList(featured)
+	}
+	return order
+}
+
+// PreferredModels returns the models that run --auto chooses first. GH_MODELS_PREFER takes precedence over the file.
+func (s *Settings) PreferredModels() []string {
+	if prefer := os.Getenv(EnvName("prefer")); prefer != "" {
+		return splitList(prefer)
+	}
+	return s.Prefer
+}
+
+// RateLimitBudgets returns the daily budget of each rate limit tier: the default budgets, with the limits in the
+// settings taking precedence.
+func (s *Settings) RateLimitBudgets() map[string]quota.Budget {
+	budgets := make(map[string]quota.Budget, len(quota.DefaultBudgets))
+	for tier, budget := range quota.DefaultBudgets {
+		budgets[tier] = budget
+	}
+	for tier, overrides := range s.Budgets {
+		budget := budgets[tier]
+		if limit, err := strconv.Atoi(overrides.RequestsPerDay); err == nil {
+			budget.RequestsPerDay = limit
+		}
+		if limit, err := strconv.Atoi(overrides.TokensPerDay); err == nil {
+			budget.TokensPerDay = limit
+		}
+		budgets[tier] = budget
+	}
+	return budgets
+}
+
+// Get returns the value of the setting with the given key, such as "model", "parameters.temperature",
+// "aliases.fast" or "profiles.triage.model". It returns false if the setting is not set.
+func (s *Settings) Get(key string) (string, bool, error) {
+	switch {
+	case key == "sort":
+		return s.Sort, s.Sort != "", nil
+	case key == "pinned":
+		return strings.Join(s.Pinned, ","), len(s.Pinned) > 0, nil
+	case key == "featured":
+		return strings.Join(s.Featured, ","), len(s.Featured) > 0, nil
+	case key == "prefer":
+		return strings.Join(s.Prefer, ","), len(s.Prefer) > 0, nil
+	case strings.HasPrefix(key, "hosts."):
+		host, field, err := parseSectionKey(key, "hosts", hostFields)
+		value := getSectionSetting(s.Hosts, host, field)
+		return value, value != "", err
+	case strings.HasPrefix(key, "providers."):
+		provider, field, err := parseSectionKey(key, "providers", providerFields)
+		value := getSectionSetting(s.Providers, provider, field)
+		return value, value != "", err
+	case strings.HasPrefix(key, "budgets."):
+		tier, field, err := parseSectionKey(key, "budgets", budgetFields)
+		value := getSectionSetting(s.Budgets, tier, field)
+		return value, value != "", err
+	}
+
+	profile, field, err := s.lookup(key, false)
+	if err != nil || profile == nil {
+		return "", false, err
+	}
+	value := ""
+	switch {
+	case field == "model":
+		value = profile.Model
+	case field == "provider":
+		value = profile.Provider
+	case field == "fallback":
+		value = strings.Join(profile.Fallback, ",")
+	case field == "system-prompt":
+		value = profile.SystemPrompt
+	case strings.HasPrefix(field, "aliases."):
+		value = s.Aliases[strings.TrimPrefix(field, "aliases.")]
+	default:
+		value = profile.Parameters[strings.TrimPrefix(field, "parameters.")]
+	}
+	return value, value != "", nil
+}
+
+// Set sets the setting with the given key. Setting a value to the empty string removes it. Lists, such as pinned, are
+// separated by commas.
+func (s *Settings) Set(key, value string) error {
+	switch {
+	case key == "sort":
+		if value != "" {
+			if err := azuremodels.ValidateSortOrder(value); err != nil {
+				return err
+			}
+		}
+		s.Sort = value
+		return nil
+	case key == "pinned":
+		s.Pinned = splitList(value)
+		return nil
+	case key == "featured":
+		s.Featured = splitList(value)
+		return nil
+	case key == "prefer":
+		s.Prefer = splitList(value)
+		return nil
+	case strings.HasPrefix(key, "hosts."):
+		host, field, err := parseSectionKey(key, "hosts", hostFields)
+		if err != nil {
+			return err
+		}
+		s.Hosts = setSectionSetting(s.Hosts, host, field, value)
+		return nil
+	case strings.HasPrefix(key, "providers."):
+		provider, field, err := parseSectionKey(key, "providers", providerFields)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(provider, azuremodels.ProviderSeparator) {
+			return fmt.Errorf("provider names can't contain '%s'", azuremodels.ProviderSeparator)
+		}
+		s.Providers = setSectionSetting(s.Providers, provider, field, value)
+		return nil
+	case strings.HasPrefix(key, "budgets."):
+		tier, field, err := parseSectionKey(key, "budgets", budgetFields)
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			if limit, err := strconv.Atoi(value); err != nil || limit < 0 {
+				return fmt.Errorf("invalid budget '%s'. Use a number of %s, or 0 for no limit", value, strings.TrimSuffix(field, "-per-day"))
+			}
+		}
+		s.Budgets = setSectionSetting(s.Budgets, tier, field, value)
+		return nil
+	}
+
+	profile, field, err := s.lookup(key, value != "")
+	if err != nil || profile == nil {
+		return err
+	}
+
+	switch {
+	case field == "model":
+		profile.Model = value
+	case field == "provider":
+		profile.Provider = value
+	case field == "fallback":
+		profile.Fallback = splitList(value)
+	case field == "system-prompt":
+		profile.SystemPrompt = value
+	case strings.HasPrefix(field, "aliases."):
+		s.Aliases = setOrDelete(s.Aliases, strings.TrimPrefix(field, "aliases."), value)
+	default:
+		profile.Parameters = setOrDelete(profile.Parameters, strings.TrimPrefix(field, "parameters."), value)
+	}
+
+	s.removeEmptyProfiles()
+	return nil
+}
+
+// ParameterName returns the name of the model parameter that the given key refers to, if any.
+func ParameterName(key string) (string, bool) {
+	if rest, ok := strings.CutPrefix(key, "profiles."); ok {
+		_, key, _ = strings.Cut(rest, ".")
+	}
+	return strings.CutPrefix(key, "parameters.")
+}
+
+// List returns every setting that is set, as key and value pairs sorted by key.
+func (s *Settings) List() [][2]string {
+	settings := listProfile("", &s.Profile)
+	for alias, model := range s.Aliases {
+		settings = append(settings, [2]string{"aliases." + alias, model})
+	}
+	for name, profile := range s.Profiles {
+		settings = append(settings, listProfile("profiles."+name+".", profile)...)
+	}
+	settings = append(settings, listSection(s.Hosts, "hosts", hostFields)...)
+	settings = append(settings, listSection(s.Providers, "providers", providerFields)...)
+	settings = append(settings, listSection(s.Budgets, "budgets", budgetFields)...)
+	if s.Sort != "" {
+		settings = append(settings, [2]string{"sort", s.Sort})
+	}
+	if len(s.Pinned) > 0 {
+		settings = append(settings, [2]string{"pinned", strings.Join(s.Pinned, ",")})
+	}
+	if len(s.Featured) > 0 {
+		settings = append(settings, [2]string{"featured", strings.Join(s.Featured, ",")})
+	}
+	if len(s.Prefer) > 0 {
+		settings = append(settings, [2]string{"prefer", strings.Join(s.Prefer, ",")})
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i][0] < settings[j][0]
+	})
+	return settings
+}
+
+// lookup returns the profile a key refers to, and the key within that profile. The profile is created if create is
+// true, otherwise it is nil if it doesn't exist.
+func (s *Settings) lookup(key string, create bool) (*Profile, string, error) {
+	invalid := fmt.Errorf("invalid key '%s'. Keys are model, provider, fallback, system-prompt, parameters.<name>, aliases.<alias>, profiles.<profile>.<key>, providers.<provider>.<key>, hosts.<hostname>.<url>, budgets.<tier>.<limit>, sort, pinned, featured, or prefer", key)
+
+	profileName, field, inProfile := "", key, false
+	if rest, ok := strings.CutPrefix(key, "profiles."); ok {
+		profileName, field, ok = strings.Cut(rest, ".")
+		if !ok || profileName == "" {
+			return nil, "", invalid
+		}
+		inProfile = true
+	}
+
+	switch {
+	case field == "model" || field == "provider" || field == "fallback" || field == "system-prompt":
+	case strings.HasPrefix(field, "parameters.") && len(field) > len("parameters."):
+	case strings.HasPrefix(field, "aliases.") && len(field) > len("aliases.") && !inProfile:
+	default:
+		return nil, "", invalid
+	}
+
+	if !inProfile {
+		return &s.Profile, field, nil
+	}
+
+	profile := s.Profiles[profileName]
+	if profile == nil && create {
+		profile = &Profile{}
+		if s.Profiles == nil {
+			s.Profiles = map[string]*Profile{}
+		}
+		s.Profiles[profileName] = profile
+	}
+	return profile, field, nil
+}
+
+// sectionSettings is implemented by the settings of a named host or provider.
+type sectionSettings interface {
+	// fields returns pointers to the settings, keyed by name.
+	fields() map[string]*string
+}
+
+var (
+	// hostFields lists the keys of the settings for each host.
+	hostFields = []string{"inference-url", "catalog-url"}
+	// providerFields lists the keys of the settings for each provider.
+	providerFields = []string{"base-url", "api-key-env", "auth-header"}
+	// budgetFields lists the keys of the settings for each rate limit tier.
+	budgetFields = []string{"requests-per-day", "tokens-per-day"}
+)
+
+func (e *HostEndpoints) fields() map[string]*string {
+	return map[string]*string{"inference-url": &e.InferenceURL, "catalog-url": &e.CatalogURL}
+}
+
+func (p *ProviderSettings) fields() map[string]*string {
+	return map[string]*string{"base-url": &p.BaseURL, "api-key-env": &p.APIKeyEnv, "auth-header": &p.AuthHeader}
+}
+
+func (b *BudgetSettings) fields() map[string]*string {
+	return map[string]*string{"requests-per-day": &b.RequestsPerDay, "tokens-per-day": &b.TokensPerDay}
+}
+
+// parseSectionKey splits a key such as "hosts.octocorp.ghe.com.inference-url" into the name of the host or provider,
+// and the setting.
+func parseSectionKey(key, section string, fields []string) (string, string, error) {
+	rest := strings.TrimPrefix(key, section+".")
+	if index := strings.LastIndex(rest, "."); index > 0 {
+		name, field := rest[:index], rest[index+1:]
+		for _, f := range fields {
+			if field == f {
+				return name, field, nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("invalid key '%s'. Keys for %s are %s.<name>.<key>, where <key> is one of: %s", key, section, section, strings.Join(fields, ", "))
+}
+
+func getSectionSetting[E any, T interface {
+	*E
+	sectionSettings
+}](values map[string]T, name, field string) string {
+	entry, ok := values[name]
+	if !ok {
+		return ""
+	}
+	return *entry.fields()[field]
+}
+
+// setSectionSetting sets a setting of the named host or provider, removing it once all its settings are empty.
+func setSectionSetting[E any, T interface {
+	*E
+	sectionSettings
+}](values map[string]T, name, field, value string) map[string]T {
+	entry, ok := values[name]
+	if !ok {
+		if value == "" {
+			return values
+		}
+		entry = T(new(E))
+		if values == nil {
+			values = map[string]T{}
+		}
+		values[name] = entry
+	}
+
+	*entry.fields()[field] = value
+	for _, v := range entry.fields() {
+		if *v != "" {
+			return values
+		}
+	}
+	delete(values, name)
+	return values
+}
+
+func listSection[E any, T interface {
+	*E
+	sectionSettings
+}](values map[string]T, section string, fields []string) [][2]string {
+	settings := [][2]string{}
+	for name, entry := range values {
+		for _, field := range fields {
+			if value := *entry.fields()[field]; value != "" {
+				settings = append(settings, [2]string{section + "." + name + "." + field, value})
+			}
+		}
+	}
+	return settings
+}
+
+func (s *Settings) removeEmptyProfiles() {
+	for name, profile := range s.Profiles {
+		if profile.Model == "" && profile.Provider == "" && len(profile.Fallback) == 0 && profile.SystemPrompt == "" && len(profile.Parameters) == 0 {
+			delete(s.Profiles, name)
+		}
+	}
+}
+
+func listProfile(prefix string, profile *Profile) [][2]string {
+	settings := [][2]string{}
+	if profile.Model != "" {
+		settings = append(settings, [2]string{prefix + "model", profile.Model})
+	}
+	if profile.Provider != "" {
+		settings = append(settings, [2]string{prefix + "provider", profile.Provider})
+	}
+	if len(profile.Fallback) > 0 {
+		settings = append(settings, [2]string{prefix + "fallback", strings.Join(profile.Fallback, ",")})
+	}
+	if profile.SystemPrompt != "" {
+		settings = append(settings, [2]string{prefix + "system-prompt", profile.SystemPrompt})
+	}
+	for name, value := range profile.Parameters {
+		settings = append(settings, [2]string{prefix + "parameters." + name, value})
+	}
+	return settings
+}
+
+func setOrDelete(values map[string]string, key, value string) map[string]string {
+	if value == "" {
+		delete(values, key)
+		return values
+	}
+	if values == nil {
+		values = map[string]string{}
+	}
+	values[key] = value
+	return values
+}
+
+// splitList splits a list of values separated by commas, leaving out empty values.
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,235 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-models/internal/quota"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettings(t *testing.T) {
+	t.Run("LoadFile returns empty settings if the file doesn't exist", func(t *testing.T) {
+		s, err := LoadFile(filepath.Join(t.TempDir(), "models.yml"))
+
+		require.NoError(t, err)
+		require.Empty(t, s.List())
+	})
+
+	t.Run("Set and Save round trip through the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gh", "models.yml")
+		s, err := LoadFile(path)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Set("model", "fast"))
+		require.NoError(t, s.Set("parameters.temperature", "0.5"))
+		require.NoError(t, s.Set("aliases.fast", "gpt-4o-mini"))
+		require.NoError(t, s.Set("profiles.triage.system-prompt", "Classify the issue."))
+		require.NoError(t, s.Set("profiles.triage.parameters.temperature", "0"))
+		require.NoError(t, s.Save())
+
+		loaded, err := LoadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, [][2]string{
+			{"aliases.fast", "gpt-4o-mini"},
+			{"model", "fast"},
+			{"parameters.temperature", "0.5"},
+			{"profiles.triage.parameters.temperature", "0"},
+			{"profiles.triage.system-prompt", "Classify the issue."},
+		}, loaded.List())
+		value, ok, err := loaded.Get("profiles.triage.parameters.temperature")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "0", value)
+	})
+
+	t.Run("setting an empty value removes the setting", func(t *testing.T) {
+		s := &Settings{}
+		require.NoError(t, s.Set("profiles.triage.model", "gpt-4o"))
+
+		require.NoError(t, s.Set("profiles.triage.model", ""))
+
+		_, ok, err := s.Get("profiles.triage.model")
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Empty(t, s.Profiles)
+	})
+
+	t.Run("rejects invalid keys", func(t *testing.T) {
+		s := &Settings{}
+		for _, key := range []string{"temperature", "parameters.", "profiles.triage", "profiles.triage.aliases.fast", "profiles..model"} {
+			require.ErrorContains(t, s.Set(key, "value"), "invalid key '"+key+"'")
+			_, _, err := s.Get(key)
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("Resolve applies the profile and environment over the defaults", func(t *testing.T) {
+		s := &Settings{
+			Profile: Profile{
+				Model:        "gpt-4o",
+				SystemPrompt: "Be brief.",
+				Parameters:   map[string]string{"temperature": "1", "max-tokens": "100"},
+			},
+			Aliases: map[string]string{"fast": "gpt-4o-mini"},
+			Profiles: map[string]*Profile{
+				"triage": {Model: "fast", Parameters: map[string]string{"temperature": "0"}},
+			},
+		}
+
+		resolved, err := s.Resolve("")
+		require.NoError(t, err)
+		require.Equal(t, "gpt-4o", resolved.Model)
+
+		resolved, err = s.Resolve("triage")
+		require.NoError(t, err)
+		require.Equal(t, "gpt-4o-mini", resolved.Model)
+		require.Equal(t, "Be brief.", resolved.SystemPrompt)
+		require.Equal(t, map[string]string{"temperature": "0", "max-tokens": "100"}, resolved.Parameters)
+
+		t.Setenv("GH_MODELS_PROFILE", "triage")
+		t.Setenv("GH_MODELS_SYSTEM_PROMPT", "Answer in French.")
+		resolved, err = s.Resolve("")
+		require.NoError(t, err)
+		require.Equal(t, "gpt-4o-mini", resolved.Model)
+		require.Equal(t, "Answer in French.", resolved.SystemPrompt)
+
+		_, err = s.Resolve("missing")
+		require.EqualError(t, err, "unknown profile 'missing'. Run 'gh models config list' to see the configured profiles")
+	})
+
+	t.Run("LoadFile reports invalid files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "models.yml")
+		require.NoError(t, os.WriteFile(path, []byte("aliases: [not, a, map]"), 0o600))
+
+		_, err := LoadFile(path)
+
+		require.ErrorContains(t, err, "failed to parse "+path)
+	})
+
+	t.Run("host endpoints", func(t *testing.T) {
+		s := &Settings{}
+
+		require.NoError(t, s.Set("hosts.octocorp.ghe.com.inference-url", "https://models.example.com/chat/completions"))
+		require.NoError(t, s.Set("hosts.octocorp.ghe.com.catalog-url", "https://catalog.example.com"))
+
+		require.Equal(t, "https://models.example.com/chat/completions", s.Hosts["octocorp.ghe.com"].InferenceURL)
+		value, ok, err := s.Get("hosts.octocorp.ghe.com.catalog-url")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "https://catalog.example.com", value)
+		require.Len(t, s.List(), 2)
+		require.EqualError(t, s.Set("hosts.octocorp.ghe.com.token", "x"), "invalid key 'hosts.octocorp.ghe.com.token'. Keys for hosts are hosts.<name>.<key>, where <key> is one of: inference-url, catalog-url")
+
+		require.NoError(t, s.Set("hosts.octocorp.ghe.com.inference-url", ""))
+		require.NoError(t, s.Set("hosts.octocorp.ghe.com.catalog-url", ""))
+		require.Empty(t, s.Hosts)
+	})
+
+	t.Run("providers", func(t *testing.T) {
+		s := &Settings{}
+		require.NoError(t, s.Set("providers.ollama.base-url", "http://localhost:11434/v1"))
+		require.NoError(t, s.Set("aliases.local", "llama3"))
+		require.NoError(t, s.Set("profiles.local.provider", "ollama"))
+		require.NoError(t, s.Set("profiles.local.model", "local"))
+		require.EqualError(t, s.Set("providers.a:b.base-url", "http://localhost"), "provider names can't contain ':'")
+
+		resolved, err := s.Resolve("local")
+		require.NoError(t, err)
+		require.Equal(t, "ollama:llama3", resolved.Model)
+		require.Equal(t, "ollama:llama3", s.ResolveModel("llama3", resolved))
+		require.Equal(t, "ollama:llama3:8b", s.ResolveModel("llama3:8b", resolved))
+		require.Equal(t, "ollama:qwen2.5:7b", s.ResolveModel("ollama:qwen2.5:7b", resolved))
+		require.Equal(t, "llama3:8b", s.ResolveModel("llama3:8b", Profile{}))
+
+		require.NoError(t, s.Set("profiles.local.provider", "missing"))
+		_, err = s.Resolve("local")
+		require.EqualError(t, err, "unknown provider 'missing'. Add it with 'gh models config set providers.missing.base-url <url>'")
+	})
+
+	t.Run("model order", func(t *testing.T) {
+		s := &Settings{}
+		require.NoError(t, s.Set("sort", "popularity"))
+		require.NoError(t, s.Set("pinned", "gpt-4o, phi-4"))
+		require.NoError(t, s.Set("featured", "llama3"))
+		require.EqualError(t, s.Set("sort", "size"), "invalid sort order 'size'. Use one of: name, popularity, publisher, context")
+
+		value, ok, err := s.Get("pinned")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "gpt-4o,phi-4", value)
+
+		order := s.ModelOrder()
+		require.Equal(t, "popularity", order.By)
+		require.Equal(t, []string{"gpt-4o", "phi-4"}, order.Pinned)
+		require.Equal(t, []string{"llama3"}, order.Featured)
+
+		t.Setenv("GH_MODELS_FEATURED", "mistral,cohere")
+		require.Equal(t, []string{"mistral", "cohere"}, s.ModelOrder().Featured)
+
+		require.NoError(t, s.Set("pinned", ""))
+		require.Empty(t, s.Pinned)
+
+		require.NoError(t, s.Set("prefer", "gpt-4o,phi-4"))
+		require.Equal(t, []string{"gpt-4o", "phi-4"}, s.PreferredModels())
+		t.Setenv("GH_MODELS_PREFER", "llama3")
+		require.Equal(t, []string{"llama3"}, s.PreferredModels())
+	})
+
+	t.Run("fallback models", func(t *testing.T) {
+		s := &Settings{}
+		require.NoError(t, s.Set("aliases.small", "gpt-4o-mini"))
+		require.NoError(t, s.Set("fallback", "small,phi-4"))
+		require.NoError(t, s.Set("profiles.local.fallback", "llama3"))
+
+		value, ok, err := s.Get("profiles.local.fallback")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "llama3", value)
+
+		resolved, err := s.Resolve("")
+		require.NoError(t, err)
+		require.Equal(t, []string{"gpt-4o-mini", "phi-4"}, resolved.Fallback)
+
+		resolved, err = s.Resolve("local")
+		require.NoError(t, err)
+		require.Equal(t, []string{"llama3"}, resolved.Fallback)
+
+		t.Setenv("GH_MODELS_FALLBACK", "small")
+		resolved, err = s.Resolve("local")
+		require.NoError(t, err)
+		require.Equal(t, []string{"gpt-4o-mini"}, resolved.Fallback)
+
+		require.NoError(t, s.Set("profiles.local.fallback", ""))
+		require.Empty(t, s.Profiles)
+	})
+
+	t.Run("budgets", func(t *testing.T) {
+		s := &Settings{}
+		require.NoError(t, s.Set("budgets.high.requests-per-day", "20"))
+		require.NoError(t, s.Set("budgets.high.tokens-per-day", "100000"))
+		require.NoError(t, s.Set("budgets.low.requests-per-day", "0"))
+		require.EqualError(t, s.Set("budgets.low.tokens-per-day", "-1"), "invalid budget '-1'. Use a number of tokens, or 0 for no limit")
+		require.Error(t, s.Set("budgets.low.minutes", "5"))
+
+		value, ok, err := s.Get("budgets.high.requests-per-day")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "20", value)
+
+		budgets := s.RateLimitBudgets()
+		require.Equal(t, quota.Budget{RequestsPerDay: 20, TokensPerDay: 100000}, budgets["high"])
+		require.Equal(t, quota.Budget{}, budgets["low"])
+		require.Equal(t, quota.DefaultBudgets["custom"], budgets["custom"])
+	})
+
+	t.Run("ParameterName", func(t *testing.T) {
+		_, ok := ParameterName("profiles.parameters.model")
+		require.False(t, ok)
+
+		name, ok := ParameterName("profiles.triage.parameters.temperature")
+		require.True(t, ok)
+		require.Equal(t, "temperature", name)
+	})
+}
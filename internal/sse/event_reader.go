@@ -7,8 +7,11 @@ import (
 	"bufio"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Reader is an interface for reading events from an SSE stream.
@@ -20,54 +23,198 @@ type Reader[T any] interface {
 	Close() error
 }
 
+const (
+	// initialBufferSize is the initial size of the buffer used to read lines from the stream.
+	initialBufferSize = 64 * 1024
+	// maxLineSize is the longest line the reader accepts. Tool calls and large JSON chunks can be well over the 64 KB
+	// that bufio.Scanner allows by default.
+	maxLineSize = 16 * 1024 * 1024
+	// errorEventName is the name of events that report an error in the middle of the stream.
+	errorEventName = "error"
+)
+
+// Event is a single event from an SSE stream.
+type Event[T any] struct {
+	// Name is the event type, from the `event` field. It is empty for events without a type.
+	Name string
+	// ID is the last event ID, from the `id` field of this or an earlier event.
+	ID string
+	// Data is the event's data, decoded from JSON.
+	Data T
+}
+
+// StreamError is an error sent by the server in the middle of an SSE stream, either as an `error` event or as data
+// with an `error` object.
+type StreamError struct {
+	// Code is the error code, if the server sent one.
+	Code string
+	// Message describes the error.
+	Message string
+}
+
+// Error returns a description of the error.
+func (e *StreamError) Error() string {
+	if e.Code == "" {
+		return "error from the server: " + e.Message
+	}
+	return fmt.Sprintf("error from the server: %s (%s)", e.Message, e.Code)
+}
+
 // EventReader streams events dynamically from an OpenAI endpoint.
 type EventReader[T any] struct {
-	reader  io.ReadCloser // Required for Closing
-	scanner *bufio.Scanner
+	reader      io.ReadCloser // Required for Closing
+	scanner     *bufio.Scanner
+	lastEventID string
+	retry       time.Duration
 }
 
 // NewEventReader creates an EventReader that provides access to messages of
 // type T from r.
 func NewEventReader[T any](r io.ReadCloser) *EventReader[T] {
-	return &EventReader[T]{reader: r, scanner: bufio.NewScanner(r)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, initialBufferSize), maxLineSize)
+	return &EventReader[T]{reader: r, scanner: scanner}
 }
 
 // Read reads the next event from the stream.
 // Returns io.EOF when there are no further events.
 func (er *EventReader[T]) Read() (T, error) {
-	// https://html.spec.whatwg.org/multipage/server-sent-events.html
+	event, err := er.ReadEvent()
+	return event.Data, err
+}
+
+// ReadEvent reads the next event from the stream, including its type and ID.
+// Returns io.EOF when there are no further events, and a *StreamError if the server reported an error.
+func (er *EventReader[T]) ReadEvent() (Event[T], error) {
+	// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+	var data strings.Builder
+	hasData := false
+	eventName := ""
+
 	for er.scanner.Scan() { // Scan while no error
 		line := er.scanner.Text() // Get the line & interpret the event stream:
 
-		if line == "" || line[0] == ':' { // If the line is blank or is a comment, skip it
+		if line == "" { // A blank line dispatches the event
+			if !hasData {
+				eventName = ""
+				continue
+			}
+			return er.dispatch(eventName, data.String())
+		}
+
+		if line[0] == ':' { // If the line is a comment, skip it
 			continue
 		}
 
-		if strings.Contains(line, ":") { // If the line contains a U+003A COLON character (:), process the field
-			tokens := strings.SplitN(line, ":", 2)
-			tokens[0], tokens[1] = strings.TrimSpace(tokens[0]), strings.TrimSpace(tokens[1])
-			var data T
-			switch tokens[0] {
-			case "data": // return the deserialized JSON object
-				if tokens[1] == "[DONE]" { // If data is [DONE], end of stream was reached
-					return data, io.EOF
-				}
-				err := json.Unmarshal([]byte(tokens[1]), &data)
-				return data, err
-			default: // Any other event type is an unexpected
-				return data, errors.New("unexpected event type: " + tokens[0])
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
 			}
-			// Unreachable
+			data.WriteString(value)
+			hasData = true
+		case "event":
+			eventName = value
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				er.lastEventID = value
+			}
+		case "retry":
+			if milliseconds, err := strconv.ParseUint(value, 10, 63); err == nil {
+				er.retry = time.Duration(milliseconds) * time.Millisecond
+			}
+		default: // Other fields are ignored, as the spec requires
 		}
 	}
 
 	scannerErr := er.scanner.Err()
+	if scannerErr != nil {
+		return Event[T]{}, scannerErr
+	}
+
+	// Be lenient with servers that close the stream without a blank line after the last event.
+	if hasData {
+		return er.dispatch(eventName, data.String())
+	}
+
+	return Event[T]{}, errors.New("incomplete stream")
+}
+
+// LastEventID returns the ID of the most recent event that had one.
+func (er *EventReader[T]) LastEventID() string {
+	return er.lastEventID
+}
 
-	if scannerErr == nil {
-		return *new(T), errors.New("incomplete stream")
+// Retry returns the reconnection time requested by the server, or 0 if the server didn't request one.
+func (er *EventReader[T]) Retry() time.Duration {
+	return er.retry
+}
+
+func (er *EventReader[T]) dispatch(eventName, data string) (Event[T], error) {
+	event := Event[T]{Name: eventName, ID: er.lastEventID}
+
+	if strings.TrimSpace(data) == "[DONE]" { // If data is [DONE], end of stream was reached
+		return event, io.EOF
+	}
+
+	if eventName == errorEventName {
+		return event, parseStreamError(data)
+	}
+
+	if streamErr := findStreamError(data); streamErr != nil {
+		return event, streamErr
+	}
+
+	err := json.Unmarshal([]byte(data), &event.Data)
+	return event, err
+}
+
+type streamErrorPayload struct {
+	Code    json.RawMessage `json:"code"`
+	Message string          `json:"message"`
+}
+
+// findStreamError returns the error in the given data, if it is an object with an `error` field.
+func findStreamError(data string) *StreamError {
+	if !strings.Contains(data, `"error"`) {
+		return nil
+	}
+
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(data), &envelope); err != nil || len(envelope.Error) == 0 || string(envelope.Error) == "null" {
+		return nil
+	}
+
+	return parseStreamError(string(envelope.Error))
+}
+
+// parseStreamError parses an error sent by the server, which may be an error object, an object with an `error`
+// field, a JSON string or plain text.
+func parseStreamError(data string) *StreamError {
+	if streamErr := findStreamError(data); streamErr != nil {
+		return streamErr
+	}
+
+	var message string
+	if err := json.Unmarshal([]byte(data), &message); err == nil {
+		return &StreamError{Message: message}
+	}
+
+	var payload streamErrorPayload
+	if err := json.Unmarshal([]byte(data), &payload); err == nil && payload.Message != "" {
+		code := strings.Trim(string(payload.Code), `"`)
+		if code == "null" {
+			code = ""
+		}
+		return &StreamError{Code: code, Message: payload.Message}
 	}
 
-	return *new(T), scannerErr
+	return &StreamError{Message: strings.TrimSpace(data)}
 }
 
 // Close closes the EventReader and any applicable inner stream state.
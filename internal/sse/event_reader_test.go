@@ -4,6 +4,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -23,17 +24,18 @@ func (br badReader) Read(p []byte) (n int, err error) {
 }
 
 func TestEventReader(t *testing.T) {
-	t.Run("invalid type", func(t *testing.T) {
+	t.Run("unknown fields are ignored", func(t *testing.T) {
 		data := []string{
 			"invaliddata: {\"name\":\"chatcmpl-7Z4kUpXX6HN85cWY28IXM4EwemLU3\",\"object\":\"chat.completion.chunk\",\"created\":1688594090,\"model\":\"gpt-4-0613\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"\"},\"finish_reason\":null}]}\n\n",
+			"data: {\"name\":\"valid\"}\n\n",
 		}
 
 		text := strings.NewReader(strings.Join(data, "\n"))
 		eventReader := NewEventReader[sampleContent](io.NopCloser(text))
 
 		firstEvent, err := eventReader.Read()
-		require.Empty(t, firstEvent)
-		require.EqualError(t, err, "unexpected event type: invaliddata")
+		require.NoError(t, err)
+		require.Equal(t, "valid", firstEvent.Name)
 	})
 
 	t.Run("bad reader", func(t *testing.T) {
@@ -62,9 +64,9 @@ func TestEventReader(t *testing.T) {
 	t.Run("spaces around areas", func(t *testing.T) {
 		buf := strings.NewReader(
 			// spaces between data
-			"data: {\"name\":\"chatcmpl-7Z4kUpXX6HN85cWY28IXM4EwemLU3\",\"nested_data\":[{\"count\":0,\"value\":\"with-spaces\"}]}\n" +
+			"data: {\"name\":\"chatcmpl-7Z4kUpXX6HN85cWY28IXM4EwemLU3\",\"nested_data\":[{\"count\":0,\"value\":\"with-spaces\"}]}\n\n" +
 				// no spaces
-				"data:{\"name\":\"chatcmpl-7Z4kUpXX6HN85cWY28IXM4EwemLU3\",\"nested_data\":[{\"count\":0,\"value\":\"without-spaces\"}]}\n",
+				"data:{\"name\":\"chatcmpl-7Z4kUpXX6HN85cWY28IXM4EwemLU3\",\"nested_data\":[{\"count\":0,\"value\":\"without-spaces\"}]}\n\n",
 		)
 
 		eventReader := NewEventReader[sampleContent](io.NopCloser(buf))
@@ -78,4 +80,98 @@ func TestEventReader(t *testing.T) {
 		require.NotEmpty(t, evt)
 		require.Equal(t, "without-spaces", evt.NestedData[0].Value)
 	})
+
+	t.Run("multi-line data is joined until a blank line", func(t *testing.T) {
+		buf := strings.NewReader(
+			": a comment\n" +
+				"data: {\"name\":\n" +
+				"data: \"multi-line\"}\n" +
+				"\n" +
+				"data: [DONE]\n\n",
+		)
+
+		eventReader := NewEventReader[sampleContent](io.NopCloser(buf))
+
+		evt, err := eventReader.Read()
+		require.NoError(t, err)
+		require.Equal(t, "multi-line", evt.Name)
+
+		_, err = eventReader.Read()
+		require.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("event name, id and retry", func(t *testing.T) {
+		buf := strings.NewReader(
+			"event: update\n" +
+				"id: 42\n" +
+				"retry: 1500\n" +
+				"data: {\"name\":\"first\"}\n\n" +
+				"data: {\"name\":\"second\"}\n\n",
+		)
+
+		eventReader := NewEventReader[sampleContent](io.NopCloser(buf))
+
+		evt, err := eventReader.ReadEvent()
+		require.NoError(t, err)
+		require.Equal(t, "update", evt.Name)
+		require.Equal(t, "42", evt.ID)
+		require.Equal(t, "first", evt.Data.Name)
+		require.Equal(t, 1500*time.Millisecond, eventReader.Retry())
+
+		evt, err = eventReader.ReadEvent()
+		require.NoError(t, err)
+		require.Empty(t, evt.Name, "the event name only applies to one event")
+		require.Equal(t, "42", evt.ID, "the last event ID carries over")
+		require.Equal(t, "second", evt.Data.Name)
+		require.Equal(t, "42", eventReader.LastEventID())
+	})
+
+	t.Run("lines longer than 64 KB", func(t *testing.T) {
+		longValue := strings.Repeat("a", 200*1024)
+		buf := strings.NewReader("data: {\"name\":\"" + longValue + "\"}\n\n")
+
+		eventReader := NewEventReader[sampleContent](io.NopCloser(buf))
+
+		evt, err := eventReader.Read()
+		require.NoError(t, err)
+		require.Equal(t, longValue, evt.Name)
+	})
+
+	t.Run("error events", func(t *testing.T) {
+		buf := strings.NewReader(
+			"data: {\"name\":\"first\"}\n\n" +
+				"event: error\n" +
+				"data: {\"error\":{\"code\":\"content_filter\",\"message\":\"The response was filtered\"}}\n\n",
+		)
+
+		eventReader := NewEventReader[sampleContent](io.NopCloser(buf))
+
+		_, err := eventReader.Read()
+		require.NoError(t, err)
+
+		_, err = eventReader.Read()
+		var streamErr *StreamError
+		require.ErrorAs(t, err, &streamErr)
+		require.Equal(t, "content_filter", streamErr.Code)
+		require.Equal(t, "The response was filtered", streamErr.Message)
+		require.EqualError(t, err, "error from the server: The response was filtered (content_filter)")
+	})
+
+	t.Run("error objects in data", func(t *testing.T) {
+		buf := strings.NewReader("data: {\"error\":{\"message\":\"Too many requests\"}}\n\n")
+
+		eventReader := NewEventReader[sampleContent](io.NopCloser(buf))
+
+		_, err := eventReader.Read()
+		require.EqualError(t, err, "error from the server: Too many requests")
+	})
+
+	t.Run("plain text error events", func(t *testing.T) {
+		buf := strings.NewReader("event: error\ndata: something went wrong\n\n")
+
+		eventReader := NewEventReader[sampleContent](io.NopCloser(buf))
+
+		_, err := eventReader.Read()
+		require.EqualError(t, err, "error from the server: something went wrong")
+	})
 }
@@ -0,0 +1,63 @@
+// Package tokens provides a local, approximate token counter for chat messages.
+package tokens
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/github/gh-models/internal/azuremodels"
+)
+
+const (
+	// charsPerToken is the average number of ASCII characters per token for common BPE tokenizers.
+	charsPerToken = 4
+	// tokensPerMessage is the overhead the chat format adds to every message.
+	tokensPerMessage = 4
+	// tokensPerReply is the overhead added to prime the assistant reply.
+	tokensPerReply = 3
+)
+
+// Estimate returns an approximate number of tokens in the given text. It is intentionally conservative: ASCII text is
+// counted at roughly four characters per token, and every other letter (for example CJK characters) as a whole token.
+func Estimate(text string) int {
+	count := 0
+	for _, word := range strings.Fields(text) {
+		asciiRunes := 0
+		for _, r := range word {
+			if r < utf8.RuneSelf {
+				asciiRunes++
+				continue
+			}
+			if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				count++
+			} else {
+				// Emoji and other symbols usually take more than one token.
+				count += 2
+			}
+		}
+		count += (asciiRunes + charsPerToken - 1) / charsPerToken
+	}
+	return count
+}
+
+// EstimateMessages returns an approximate number of tokens the given messages use as a chat completion prompt.
+func EstimateMessages(messages []azuremodels.ChatMessage) int {
+	if len(messages) == 0 {
+		return 0
+	}
+	count := tokensPerReply
+	for _, message := range messages {
+		count += EstimateMessage(message)
+	}
+	return count
+}
+
+// EstimateMessage returns an approximate number of tokens a single message uses, including the chat format overhead.
+func EstimateMessage(message azuremodels.ChatMessage) int {
+	count := tokensPerMessage
+	if message.Content != nil {
+		count += Estimate(*message.Content)
+	}
+	return count
+}
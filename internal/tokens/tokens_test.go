@@ -0,0 +1,33 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokens(t *testing.T) {
+	t.Run("Estimate", func(t *testing.T) {
+		require.Equal(t, 0, Estimate(""))
+		require.Equal(t, 0, Estimate("   \n\t"))
+		require.Equal(t, 1, Estimate("hi"))
+		require.Equal(t, 6, Estimate("hello there world"))
+		require.Equal(t, 5, Estimate("internationalization"))
+		require.Equal(t, 4, Estimate("日本語で"))
+	})
+
+	t.Run("EstimateMessages", func(t *testing.T) {
+		require.Equal(t, 0, EstimateMessages(nil))
+
+		messages := []azuremodels.ChatMessage{
+			{Role: azuremodels.ChatMessageRoleSystem, Content: util.Ptr("be brief")},
+			{Role: azuremodels.ChatMessageRoleUser, Content: util.Ptr("hello there world")},
+			{Role: azuremodels.ChatMessageRoleAssistant},
+		}
+
+		// 3 for the reply, 4 per message, plus 3 + 6 for the content.
+		require.Equal(t, 3+4*3+3+6, EstimateMessages(messages))
+	})
+}
@@ -0,0 +1,94 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/settings"
+)
+
+// AnnotationSkipClient is a command annotation that stops the root command from creating a models client before
+// running it, for commands that work without one.
+const AnnotationSkipClient = "skipClient"
+
+// Auth describes the GitHub host the extension uses, and the token it authenticates with.
+type Auth struct {
+	// Host is the GitHub host.
+	Host string
+	// HostSource describes where the host was taken from.
+	HostSource string
+	// Token is the token for the host, or empty if there is none.
+	Token string
+	// TokenSource describes where the token was taken from.
+	TokenSource string
+	// Endpoints holds the GitHub Models API URLs for the host, or nil if they are not known.
+	Endpoints *azuremodels.AzureClientConfig
+}
+
+// ResolveAuth returns the host and token to use. The hostname, from the --hostname flag, takes precedence over GH_HOST,
+// which takes precedence over the default host in the gh config. The token comes from GH_TOKEN, then GITHUB_TOKEN
+// (GH_ENTERPRISE_TOKEN and GITHUB_ENTERPRISE_TOKEN for enterprise hosts), then the token stored by `gh auth login`.
+// API URLs for the host in the settings take precedence over the built-in ones.
+func ResolveAuth(hostname string, userSettings *settings.Settings) *Auth {
+	a := &Auth{Host: hostname, HostSource: "--hostname"}
+	if a.Host == "" {
+		a.Host, a.HostSource = auth.DefaultHost()
+	}
+	a.Host = auth.NormalizeHostname(a.Host)
+	a.Token, a.TokenSource = auth.TokenForHost(a.Host)
+
+	a.Endpoints, _ = azuremodels.NewAzureClientConfigForHost(a.Host)
+	if endpoints := userSettings.Hosts[a.Host]; endpoints != nil {
+		builtIn := a.Endpoints
+		if builtIn == nil {
+			builtIn = &azuremodels.AzureClientConfig{}
+		}
+		inferenceURL, catalogURL := endpoints.InferenceURL, endpoints.CatalogURL
+		if inferenceURL == "" {
+			inferenceURL = builtIn.InferenceURL
+		}
+		if catalogURL == "" {
+			catalogURL = builtIn.AzureAiStudioURL
+		}
+		if inferenceURL != "" && catalogURL != "" {
+			a.Endpoints = azuremodels.NewAzureClientConfig(inferenceURL, catalogURL)
+		}
+	}
+
+	return a
+}
+
+// DescribeHostSource returns a description of where the host was taken from, for display.
+func (a *Auth) DescribeHostSource() string {
+	switch a.HostSource {
+	case "hosts":
+		return "gh config"
+	case "default":
+		return "default"
+	}
+	return a.HostSource
+}
+
+// DescribeTokenSource returns a description of where the token was taken from, for display.
+func (a *Auth) DescribeTokenSource() string {
+	switch a.TokenSource {
+	case "oauth_token":
+		return "gh config"
+	case "gh":
+		return "gh auth token"
+	}
+	return a.TokenSource
+}
+
+// MaskedToken returns the token with everything but its prefix, such as "gho_", masked out, so it is safe to display.
+func (a *Auth) MaskedToken() string {
+	if a.Token == "" {
+		return ""
+	}
+	prefix := ""
+	if index := strings.Index(a.Token, "_"); index >= 0 && index < 10 {
+		prefix = a.Token[:index+1]
+	}
+	return prefix + strings.Repeat("*", min(len(a.Token)-len(prefix), 36))
+}
@@ -2,11 +2,16 @@
 package command
 
 import (
+	"context"
 	"io"
 
 	"github.com/cli/go-gh/v2/pkg/tableprinter"
 	"github.com/cli/go-gh/v2/pkg/term"
 	"github.com/github/gh-models/internal/azuremodels"
+	"github.com/github/gh-models/internal/catalog"
+	"github.com/github/gh-models/internal/picker"
+	"github.com/github/gh-models/internal/quota"
+	"github.com/github/gh-models/internal/settings"
 	"github.com/github/gh-models/pkg/util"
 )
 
@@ -22,11 +27,20 @@ type Config struct {
 	IsTerminalOutput bool
 	// TerminalWidth is the width of the terminal.
 	TerminalWidth int
+	// Settings holds the user's persisted settings.
+	Settings *settings.Settings
+	// Auth describes the GitHub host and token in use, once they have been resolved.
+	Auth *Auth
+
+	themeFunc func() string
+	theme     string
+	// recentPath is where recently used models are remembered. They aren't remembered if it is empty.
+	recentPath string
 }
 
 // NewConfig returns a new command configuration.
 func NewConfig(out, errOut io.Writer, client azuremodels.Client, isTerminalOutput bool, width int) *Config {
-	return &Config{Out: out, ErrOut: errOut, Client: client, IsTerminalOutput: isTerminalOutput, TerminalWidth: width}
+	return &Config{Out: out, ErrOut: errOut, Client: client, IsTerminalOutput: isTerminalOutput, TerminalWidth: width, Settings: &settings.Settings{}}
 }
 
 // NewConfigWithTerminal returns a new command configuration using the given terminal.
@@ -38,7 +52,22 @@ func NewConfigWithTerminal(terminal term.Term, client azuremodels.Client) *Confi
 		Client:           client,
 		IsTerminalOutput: terminal.IsTerminalOutput(),
 		TerminalWidth:    width,
+		Settings:         &settings.Settings{},
+		themeFunc:        terminal.Theme,
+		recentPath:       picker.RecentPath(),
+	}
+}
+
+// Theme returns the theme of the terminal: "light", "dark" or "none". Detecting the theme queries the terminal, so it
+// is only done the first time it is needed.
+func (c *Config) Theme() string {
+	if c.theme == "" {
+		c.theme = "none"
+		if c.themeFunc != nil {
+			c.theme = c.themeFunc()
+		}
 	}
+	return c.theme
 }
 
 // NewTablePrinter initializes a table printer with terminal mode and terminal width.
@@ -50,3 +79,79 @@ func (c *Config) NewTablePrinter() tableprinter.TablePrinter {
 func (c *Config) WriteToOut(message string) {
 	util.WriteToOut(c.Out, message)
 }
+
+// NewCatalogLoader returns a loader for the model catalog, which is cached separately for each GitHub host.
+func (c *Config) NewCatalogLoader() *catalog.Loader {
+	host := ""
+	if c.Auth != nil {
+		host = c.Auth.Host
+	}
+	return catalog.NewLoader(c.Client, catalog.CachePath(host))
+}
+
+// NewQuotaTracker returns the tracker of the daily budgets of the rate limit tiers, whose usage is recorded separately
+// for each GitHub host.
+func (c *Config) NewQuotaTracker() *quota.Tracker {
+	host := ""
+	if c.Auth != nil {
+		host = c.Auth.Host
+	}
+	return quota.NewTracker(quota.UsagePath(host), c.Settings.RateLimitBudgets())
+}
+
+// SortModels sorts the given models in place, with the user's pinned and featured models first. The models are sorted
+// by the given order, or by the order in the user's settings if it is empty. Sorting by context size loads the model
+// catalog, since model summaries don't include it.
+func (c *Config) SortModels(ctx context.Context, models []*azuremodels.ModelSummary, by string) error {
+	order := c.Settings.ModelOrder()
+	if by != "" {
+		order.By = by
+	}
+	if order.By != "" {
+		if err := azuremodels.ValidateSortOrder(order.By); err != nil {
+			return err
+		}
+	}
+
+	if order.By == azuremodels.SortByContext {
+		loaded, err := c.NewCatalogLoader().Load(ctx, false)
+		if err != nil {
+			return err
+		}
+		order.ContextSize = catalogContextSize(loaded)
+	}
+
+	order.Sort(models)
+	return nil
+}
+
+// PickModel prompts to select one of the given chat models, with the recently used models first and a preview of the
+// highlighted model. The preview includes the context size from the cached catalog, if there is one. The catalog isn't
+// fetched, since fetching the details of every model would hold up the prompt.
+func (c *Config) PickModel(ctx context.Context, models []*azuremodels.ModelSummary) (*azuremodels.ModelSummary, error) {
+	var contextSize func(*azuremodels.ModelSummary) int
+	if loaded, err := c.NewCatalogLoader().Cached(); err == nil {
+		contextSize = catalogContextSize(loaded)
+	}
+
+	return picker.New(models, picker.ReadRecent(c.recentPath), contextSize).Pick()
+}
+
+// RememberModel records that the model with the given name was used, so that it is offered first by PickModel.
+// Failing to record it isn't an error, since the list of recent models is only a convenience.
+func (c *Config) RememberModel(name string) {
+	if c.recentPath != "" {
+		_ = picker.AddRecent(c.recentPath, name)
+	}
+}
+
+// catalogContextSize returns a function that looks up the input token limit of a model in the given catalog, or 0 if
+// it is unknown.
+func catalogContextSize(loaded *catalog.Catalog) func(*azuremodels.ModelSummary) int {
+	return func(model *azuremodels.ModelSummary) int {
+		if found := loaded.Find(model.Name); found != nil && found.Details != nil {
+			return found.Details.MaxInputTokens
+		}
+		return 0
+	}
+}